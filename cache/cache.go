@@ -19,10 +19,17 @@ func InitCache(params CacheParams) error {
 }
 
 func CreateFileQueue(params CacheParams) (*utils.FileQueue, error) {
-	fileList, err := params.ReadDirFunc(params.CachePath)
+	entries, err := params.ReadDirFunc(params.CachePath)
 	if err != nil {
 		return nil, err
 	}
+	// Sub directories hold other cached state, such as schema snapshots.
+	fileList := make([]os.DirEntry, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			fileList = append(fileList, entry)
+		}
+	}
 	sort.Slice(fileList, func(i, j int) bool {
 		file1Stat, err := fileList[i].Info()
 		if err != nil {
@@ -44,12 +51,25 @@ func CreateFileQueue(params CacheParams) (*utils.FileQueue, error) {
 	}
 
 	for queue.Length > int(params.MaxNumberQueries) {
-		queue.RemoveAndDeque(params.CachePath, params.RemoveFunc)
+		evict(queue, params)
 	}
 
 	return queue, nil
 }
 
+// evict removes the query at the head of the queue along with its metadata.
+func evict(queue *utils.FileQueue, params CacheParams) error {
+	fileName, err := queue.Peak()
+	if err != nil {
+		return err
+	}
+	err = queue.RemoveAndDeque(params.CachePath, params.RemoveFunc)
+	if err != nil {
+		return err
+	}
+	return RemoveMetadata(params, fileName)
+}
+
 func CreateAndEnque(queue *utils.FileQueue, params CacheParams, editFunc EditFileFunc) string {
 	params.Logger.Debug("VAR:", "queue.length", queue.Length)
 	fileName := uuid.New().String() + ".sql"
@@ -61,7 +81,7 @@ func CreateAndEnque(queue *utils.FileQueue, params CacheParams, editFunc EditFil
 		editFunc(fileName, params)
 	} else {
 		params.Logger.Debug("Replace a file")
-		err := queue.RemoveAndDeque(params.CachePath, params.RemoveFunc)
+		err := evict(queue, params)
 		if err != nil {
 			params.Logger.Error("Could not remve and deque")
 			panic(err)
@@ -79,6 +99,19 @@ func CreateAndEnque(queue *utils.FileQueue, params CacheParams, editFunc EditFil
 	return fileName
 }
 
+// CreateAndEnqueWithContents behaves like CreateAndEnque but seeds the new file
+// with contents before handing it to the editor.
+func CreateAndEnqueWithContents(queue *utils.FileQueue, params CacheParams, contents string, editFunc EditFileFunc) string {
+	writeThenEdit := func(fileName string, params CacheParams) error {
+		err := params.WriteFileFunc(filepath.Join(params.CachePath, fileName), []byte(contents), 0644)
+		if err != nil {
+			return err
+		}
+		return editFunc(fileName, params)
+	}
+	return CreateAndEnque(queue, params, writeThenEdit)
+}
+
 func EditFile(fileName string, params CacheParams) error {
 	// Open editor in blocking mode
 	cmd := params.CommandFunc(params.Editor, filepath.Join(params.CachePath, fileName))
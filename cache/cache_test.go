@@ -2,8 +2,10 @@ package cache
 
 import (
 	"io"
+	"io/fs"
 	"log/slog"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -137,3 +139,36 @@ func TestCreateAndEnque(t *testing.T) {
 	assert.Contains(t, result, ".sql")
 
 }
+
+func TestEvictionRemovesMetadata(t *testing.T) {
+	var removed []string
+	mockRemoveFunc := func(name string) error {
+		removed = append(removed, name)
+		if filepath.Ext(name) == ".json" {
+			return fs.ErrNotExist
+		}
+		return nil
+	}
+	mockReadDirFunc := func(name string) ([]os.DirEntry, error) {
+		entry1 := mockDirEntry{"1.sql", time.Now().Add(time.Second * -100)}
+		entry2 := mockDirEntry{"2.sql", time.Now().Add(time.Second * 100)}
+		return []os.DirEntry{&entry1, &entry2}, nil
+	}
+
+	mockParam := CacheParams{
+		CachePath:        "test",
+		ReadDirFunc:      mockReadDirFunc,
+		RemoveFunc:       mockRemoveFunc,
+		Logger:           slog.Default(),
+		MaxNumberQueries: 1,
+	}
+
+	queue, err := CreateFileQueue(mockParam)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, queue.Length)
+	assert.Len(t, removed, 2)
+	fileName := filepath.Base(removed[0])
+	assert.Equal(t, filepath.Join("test", fileName), removed[0])
+	assert.Equal(t, MetadataPath(mockParam, fileName), removed[1])
+}
@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"example.com/termquery/constants"
+)
+
+// QueryMetadata is viewer state remembered for a cached query.
+type QueryMetadata struct {
+	ColumnOrder   []string `json:"column_order,omitempty"`
+	PinnedColumns []string `json:"pinned_columns,omitempty"`
+}
+
+func MetadataPath(params CacheParams, fileName string) string {
+	return filepath.Join(params.CachePath, constants.MetadataCacheDirectory, fileName+".json")
+}
+
+// ReadMetadata returns the metadata stored for fileName, which is empty if
+// nothing has been stored yet.
+func ReadMetadata(params CacheParams, fileName string) (QueryMetadata, error) {
+	var metadata QueryMetadata
+	data, err := params.ReadFileFunc(MetadataPath(params, fileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return metadata, nil
+	}
+	if err != nil {
+		return metadata, err
+	}
+	err = json.Unmarshal(data, &metadata)
+	return metadata, err
+}
+
+// RemoveMetadata deletes the metadata stored for fileName, if there is any.
+func RemoveMetadata(params CacheParams, fileName string) error {
+	err := params.RemoveFunc(MetadataPath(params, fileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func WriteMetadata(params CacheParams, fileName string, metadata QueryMetadata) error {
+	err := params.MkdirFunc(filepath.Dir(MetadataPath(params, fileName)), os.ModePerm)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+	return params.WriteFileFunc(MetadataPath(params, fileName), data, 0644)
+}
@@ -0,0 +1,42 @@
+package cache
+
+import (
+	"io/fs"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetadataRoundTrip(t *testing.T) {
+	files := map[string][]byte{}
+	params := CacheParams{
+		CachePath: "cache",
+		Logger:    slog.Default(),
+		MkdirFunc: func(path string, perm os.FileMode) error { return nil },
+		WriteFileFunc: func(name string, data []byte, perm os.FileMode) error {
+			files[name] = data
+			return nil
+		},
+		ReadFileFunc: func(name string) ([]byte, error) {
+			data, ok := files[name]
+			if !ok {
+				return nil, fs.ErrNotExist
+			}
+			return data, nil
+		},
+	}
+
+	metadata, err := ReadMetadata(params, "query.sql")
+	assert.Nil(t, err)
+	assert.Equal(t, QueryMetadata{}, metadata)
+
+	want := QueryMetadata{ColumnOrder: []string{"b", "a"}, PinnedColumns: []string{"a"}}
+	assert.Nil(t, WriteMetadata(params, "query.sql", want))
+	assert.Contains(t, files, MetadataPath(params, "query.sql"))
+
+	metadata, err = ReadMetadata(params, "query.sql")
+	assert.Nil(t, err)
+	assert.Equal(t, want, metadata)
+}
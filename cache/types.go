@@ -24,6 +24,8 @@ type CacheParams struct {
 	ReadDirFunc      utils.ReadDirFunc
 	MkdirFunc        utils.MkdirFunc
 	StatFunc         utils.StatFunc
+	WriteFileFunc    utils.WriteFileFunc
+	ReadFileFunc     utils.ReadFileFunc
 }
 
 type CommandFunc func(name string, arg ...string) Command
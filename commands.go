@@ -0,0 +1,497 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"example.com/termquery/cache"
+	"example.com/termquery/config"
+	"example.com/termquery/constants"
+	"example.com/termquery/format"
+	"example.com/termquery/lsp"
+	"example.com/termquery/schema"
+	"example.com/termquery/sql"
+	"example.com/termquery/theme"
+	"example.com/termquery/utils"
+)
+
+// application carries the settings shared by every subcommand.
+type application struct {
+	logger       *slog.Logger
+	configParams config.ConfigParams
+	config       config.Config
+	overrides    []config.Setting // settings given as flags
+	cacheParams  cache.CacheParams
+	keys         sql.KeyMap
+}
+
+func (a application) run(args []string) error {
+	if len(args) == 0 {
+		return a.runMostRecentQuery()
+	}
+	switch args[0] {
+	case "schema":
+		return a.runSchema(args[1:])
+	case "lsp":
+		return a.runLanguageServer()
+	case "explain":
+		return a.runExplain(args[1:])
+	case "fmt":
+		return a.runFormat(args[1:])
+	case "config":
+		return a.runConfig(args[1:])
+	case "profile":
+		return a.runProfile(args[1:])
+	default:
+		return fmt.Errorf("unknown command %s", args[0])
+	}
+}
+
+func (a application) connection() (*sql.DatabricksConnection, error) {
+	return a.profileConnection(a.config.DefaultProfile)
+}
+
+// profileConnection connects with the profile called name.
+func (a application) profileConnection(name string) (*sql.DatabricksConnection, error) {
+	if err := config.CheckProfilesPermissions(a.configParams); err != nil {
+		fmt.Fprintln(os.Stderr, "Warning:", err)
+	}
+	profile, err := config.GetProfile(a.configParams, name)
+	if err != nil {
+		return nil, err
+	}
+	return &sql.DatabricksConnection{
+		AccessToken:    profile.AccessToken,
+		HttpPath:       profile.HttpPath,
+		ServerHostname: profile.ServerHostname,
+		AuthType:       profile.AuthType,
+		ClientID:       profile.ClientID,
+		ClientSecret:   profile.ClientSecret,
+		TokenCacheDir:  filepath.Join(a.cacheParams.CachePath, constants.TokenCacheDirectory),
+		Logger:         a.logger,
+	}, nil
+}
+
+// runMostRecentQuery reopens the most recent cached query in the editor and runs it.
+func (a application) runMostRecentQuery() error {
+	connection, err := a.connection()
+	if err != nil {
+		return err
+	}
+	queue, err := cache.CreateFileQueue(a.cacheParams)
+	if err != nil {
+		return err
+	}
+
+	// file_name := cache.CreateAndEnque(queue, cacheParams, cache.EditFile)
+	fileName := cache.EditMostRecentFile(queue, a.cacheParams, cache.EditFile)
+	a.formatOnSave(fileName)
+	runQueryAndDisplay(a.cacheParams, fileName, connection, a.keys, a.logger)
+	return nil
+}
+
+func (a application) snapshotParams() schema.SnapshotParams {
+	return schema.SnapshotParams{
+		Logger:        a.logger,
+		CachePath:     a.cacheParams.CachePath,
+		Profile:       a.config.DefaultProfile,
+		TTL:           a.config.SchemaCacheTTL(),
+		NowFunc:       time.Now,
+		ReadFileFunc:  os.ReadFile,
+		WriteFileFunc: os.WriteFile,
+		MkdirFunc:     os.MkdirAll,
+	}
+}
+
+// runSchema opens the schema browser and runs a starter query against the
+// table the user picks. `schema refresh` rebuilds the cached snapshot instead.
+func (a application) runSchema(args []string) error {
+	connection, err := a.connection()
+	if err != nil {
+		return err
+	}
+
+	if len(args) > 0 && args[0] == "refresh" {
+		snapshot, err := schema.RefreshSnapshot(connection, a.snapshotParams())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Cached %d tables for profile %s\n", len(snapshot.Tables), snapshot.Profile)
+		return nil
+	} else if len(args) > 0 {
+		return fmt.Errorf("unknown schema command %s", args[0])
+	}
+
+	var source schema.Source = schema.ConnectionSource{Connection: connection}
+	snapshot, err := schema.LoadSnapshot(connection, a.snapshotParams())
+	if err == nil {
+		source = schema.SnapshotSource{Snapshot: snapshot}
+	} else {
+		a.logger.Warn("Falling back to live schema lookups", "error", err)
+	}
+
+	table, err := schema.RunSchemaBrowser(source)
+	if err != nil {
+		return err
+	}
+	if table == nil {
+		return nil
+	}
+
+	queue, err := cache.CreateFileQueue(a.cacheParams)
+	if err != nil {
+		return err
+	}
+	fileName := cache.CreateAndEnqueWithContents(queue, a.cacheParams, table.SelectStatement()+"\n", cache.EditFile)
+	a.formatOnSave(fileName)
+	runQueryAndDisplay(a.cacheParams, fileName, connection, a.keys, a.logger)
+	return nil
+}
+
+// runLanguageServer serves completions from the cached schema snapshot over
+// stdio, using EXPLAIN to report errors when a file is opened or saved.
+func (a application) runLanguageServer() error {
+	connection, err := a.connection()
+	if err != nil {
+		return err
+	}
+	snapshot, err := schema.LoadSnapshot(connection, a.snapshotParams())
+	if err != nil {
+		a.logger.Error("No schema snapshot available for completion", "error", err)
+	}
+	server := lsp.Server{
+		Snapshot: snapshot,
+		Explain: func(query string) error {
+			return sql.DryRun(connection, query)
+		},
+		Logger: a.logger,
+	}
+	return server.Serve(os.Stdin, os.Stdout)
+}
+
+// resolveQueryFile finds the file named by a cache id (with or without the
+// .sql extension) or a path, defaulting to the most recent cached query.
+func (a application) resolveQueryFile(arg string) (string, error) {
+	if arg == "" {
+		queue, err := cache.CreateFileQueue(a.cacheParams)
+		if err != nil {
+			return "", err
+		}
+		fileName, err := queue.Peak()
+		if err != nil {
+			return "", fmt.Errorf("no cached queries")
+		}
+		return filepath.Join(a.cacheParams.CachePath, fileName), nil
+	}
+	candidates := []string{
+		filepath.Join(a.cacheParams.CachePath, arg),
+		filepath.Join(a.cacheParams.CachePath, strings.TrimSuffix(arg, ".sql")+".sql"),
+		arg,
+	}
+	for _, candidate := range candidates {
+		if utils.FileExists(candidate, a.cacheParams.StatFunc) {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("no cached query or file matches %s", arg)
+}
+
+// runExplain shows the plan of a cached query without running it.
+func (a application) runExplain(args []string) error {
+	flags := flag.NewFlagSet("explain", flag.ContinueOnError)
+	extended := flags.Bool("extended", false, "include the logical plans")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	filePath, err := a.resolveQueryFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	query, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	connection, err := a.connection()
+	if err != nil {
+		return err
+	}
+
+	mode := sql.ExplainFormatted
+	if *extended {
+		mode = sql.ExplainExtended
+	}
+	plan, err := sql.Explain(connection, string(query), mode)
+	if err != nil {
+		return err
+	}
+	// the plan is still worth showing without statistics
+	cost, _ := sql.Explain(connection, string(query), sql.ExplainCost)
+	return sql.PrintPlanTea(plan, cost)
+}
+
+// formatFile rewrites filePath in place if formatting changes it.
+func formatFile(filePath string) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	formatted := format.Format(string(data))
+	if formatted == string(data) {
+		return nil
+	}
+	return os.WriteFile(filePath, []byte(formatted), 0644)
+}
+
+// formatOnSave formats a cached query after editing when format_on_save is set.
+func (a application) formatOnSave(fileName string) {
+	if !a.config.FormatOnSave {
+		return
+	}
+	if err := formatFile(filepath.Join(a.cacheParams.CachePath, fileName)); err != nil {
+		a.logger.Error("Could not format query", "file", fileName, "error", err)
+	}
+}
+
+// runFormat formats a cached query or SQL file in place.
+func (a application) runFormat(args []string) error {
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	stdout := flags.Bool("stdout", false, "print the result instead of rewriting the file")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	filePath, err := a.resolveQueryFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if *stdout {
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		fmt.Print(format.Format(string(data)))
+		return nil
+	}
+	return formatFile(filePath)
+}
+
+// runConfig shows and changes the settings in config.yaml.
+func (a application) runConfig(args []string) error {
+	fileName := filepath.Join(a.configParams.ConfigPath, constants.ConfigFileName)
+	command := ""
+	if len(args) > 0 {
+		command = args[0]
+	}
+	switch command {
+	case "path":
+		fmt.Println(fileName)
+		return nil
+	case "list", "":
+		settings, err := config.Settings(a.configParams, a.config, a.overrides)
+		if err != nil {
+			return err
+		}
+		printSettings(settings)
+		return nil
+	case "get":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: config get <setting>")
+		}
+		return a.getSetting(args[1])
+	case "set":
+		if len(args) < 3 {
+			return fmt.Errorf("usage: config set <setting> <value>...")
+		}
+		return a.setSetting(fileName, args[1], args[2:])
+	case "edit":
+		cmd := a.cacheParams.CommandFunc(a.cacheParams.Editor, fileName)
+		if err := cmd.Run(); err != nil {
+			return err
+		}
+		cfg, err := config.Load(a.configParams)
+		if err == nil {
+			err = validateSettings(cfg)
+		}
+		if err != nil {
+			return fmt.Errorf("%s needs fixing: %w", fileName, err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown config command %s", command)
+	}
+}
+
+// getSetting prints the value of a setting, or every setting nested under it.
+func (a application) getSetting(key string) error {
+	settings, err := config.Settings(a.configParams, a.config, a.overrides)
+	if err != nil {
+		return err
+	}
+	var nested []config.Setting
+	for _, setting := range settings {
+		if setting.Key == key {
+			fmt.Println(setting.Value)
+			return nil
+		}
+		if strings.HasPrefix(setting.Key, key+".") {
+			nested = append(nested, setting)
+		}
+	}
+	if len(nested) == 0 {
+		return fmt.Errorf("unknown setting %s", key)
+	}
+	printSettings(nested)
+	return nil
+}
+
+// setSetting writes a setting to the config file, leaving the file untouched
+// if the result would not load.
+func (a application) setSetting(fileName string, key string, values []string) error {
+	contents, err := a.configParams.ReadFileFunc(fileName)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	contents, err = config.SetValue(contents, key, values)
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Parse(fileName, contents)
+	if err == nil {
+		err = validateSettings(cfg)
+	}
+	if err != nil {
+		return err
+	}
+	if err := a.configParams.WriteFileFunc(fileName, contents, 0644); err != nil {
+		return err
+	}
+	for _, o := range config.EnvOverrides(a.configParams.GetEnvFunc) {
+		if o.Key == key {
+			fmt.Printf("%s is set, so it still overrides %s\n", o.Origin, key)
+		}
+	}
+	return nil
+}
+
+// validateSettings checks the key bindings and themes, which the config
+// package leaves to the packages that use them.
+func validateSettings(cfg config.Config) error {
+	if _, err := sql.NewKeyMap(cfg.Keymap); err != nil {
+		return fmt.Errorf("keymap: %w", err)
+	}
+	if _, err := theme.Resolve(cfg.Theme, cfg.Themes, func() bool { return true }); err != nil {
+		return fmt.Errorf("theme: %w", err)
+	}
+	return nil
+}
+
+func printSettings(settings []config.Setting) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, setting := range settings {
+		source := string(setting.Source)
+		if setting.Origin != "" {
+			source += " (" + setting.Origin + ")"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", setting.Key, setting.Value, source)
+	}
+	w.Flush()
+}
+
+// runProfile manages the connection profiles in the profiles file.
+func (a application) runProfile(args []string) error {
+	command, name := "list", ""
+	if len(args) > 0 {
+		command = args[0]
+	}
+	if len(args) > 1 {
+		name = args[1]
+	}
+	switch command {
+	case "list":
+		profiles, err := config.LoadProfiles(a.configParams)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		databricksProfiles, err := config.LoadDatabricksProfiles(a.configParams)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		listed := map[string]bool{}
+		list := func(p config.Profile, source string) {
+			// a profile of the same name in the profiles file hides the Databricks CLI one
+			if listed[p.Name] {
+				return
+			}
+			listed[p.Name] = true
+			marker := " "
+			if p.Name == a.config.DefaultProfile {
+				marker = "*"
+			}
+			authType := p.AuthType
+			if authType == "" {
+				authType = "pat"
+			}
+			fmt.Fprintf(w, "%s %s\t%s\t%s\t%s\t%s\n", marker, p.Name, p.ServerHostname, p.HttpPath, authType, source)
+		}
+		for _, p := range profiles {
+			list(p, "")
+		}
+		for _, p := range databricksProfiles {
+			list(p, a.configParams.DatabricksConfigPath)
+		}
+		return w.Flush()
+	case "add":
+		profile, ok, err := promptProfile(name)
+		if err != nil || !ok {
+			return err
+		}
+		if err := config.AddProfile(a.configParams, profile); err != nil {
+			return err
+		}
+		fmt.Printf("Added profile %s\n", profile.Name)
+		return nil
+	case "remove":
+		if name == "" {
+			return fmt.Errorf("usage: profile remove <name>")
+		}
+		if err := config.RemoveProfile(a.configParams, name); err != nil {
+			return err
+		}
+		fmt.Printf("Removed profile %s\n", name)
+		if name == a.config.DefaultProfile {
+			fmt.Println("It was the default profile, pick another with profile set-default")
+		}
+		return nil
+	case "set-default":
+		if name == "" {
+			return fmt.Errorf("usage: profile set-default <name>")
+		}
+		if _, err := config.GetProfile(a.configParams, name); err != nil {
+			return err
+		}
+		fileName := filepath.Join(a.configParams.ConfigPath, constants.ConfigFileName)
+		return a.setSetting(fileName, "default_profile", []string{name})
+	case "test":
+		if name == "" {
+			name = a.config.DefaultProfile
+		}
+		connection, err := a.profileConnection(name)
+		if err != nil {
+			return err
+		}
+		latency, err := sql.Ping(connection, time.Now)
+		if err != nil {
+			return fmt.Errorf("profile %s: %w", name, err)
+		}
+		fmt.Printf("Connected with profile %s in %s\n", name, latency.Round(time.Millisecond))
+		return nil
+	default:
+		return fmt.Errorf("unknown profile command %s", command)
+	}
+}
@@ -1,16 +1,79 @@
 package config
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path"
-	"strconv"
-	"strings"
+	"slices"
+	"time"
 
 	"example.com/termquery/constants"
 	"example.com/termquery/utils"
+
+	"gopkg.in/yaml.v3"
 )
 
+// Config holds the settings from config.yaml. Settings left out of the file
+// keep their value from Default.
+type Config struct {
+	MaxNumberHistoricalQueries int                          `yaml:"max_number_historical_queries"`
+	ForceUseNeovim             bool                         `yaml:"force_use_neovim"`
+	DefaultProfile             string                       `yaml:"default_profile"`
+	SchemaCacheTTLMinutes      int                          `yaml:"schema_cache_ttl_minutes"`
+	FormatOnSave               bool                         `yaml:"format_on_save"`
+	Theme                      string                       `yaml:"theme"`
+	Themes                     map[string]map[string]string `yaml:"themes,omitempty"`
+	Keymap                     map[string][]string          `yaml:"keymap,omitempty"`
+}
+
+func Default() Config {
+	return Config{
+		MaxNumberHistoricalQueries: int(constants.DefaultMaxNumberOfHistoricalQueries),
+		DefaultProfile:             constants.DefaultProfileName,
+		SchemaCacheTTLMinutes:      constants.DefaultSchemaCacheTTLMinutes,
+		Theme:                      constants.DefaultTheme,
+	}
+}
+
+func (c Config) SchemaCacheTTL() time.Duration {
+	return time.Duration(c.SchemaCacheTTLMinutes) * time.Minute
+}
+
+// defaultConfig is written on first run. It documents the settings that are
+// left out.
+var defaultConfig = fmt.Sprintf(`# termquery configuration
+
+# How many queries to keep in the cache.
+max_number_historical_queries: %d
+# Edit queries with nvim, even when $EDITOR is set.
+force_use_neovim: false
+# The profile from the profiles file to connect with.
+default_profile: %s
+# How long a cached schema snapshot is used before it is fetched again.
+schema_cache_ttl_minutes: %d
+# Format a query when the editor is closed.
+format_on_save: false
+# auto, dark, light, high-contrast or one of themes.
+theme: %s
+
+# themes:
+#   solarized:
+#     base: light
+#     header: "#268bd2"
+#
+# keymap:
+#   sort: [s, ctrl+s]
+#   quit: [Q]
+`,
+	constants.DefaultMaxNumberOfHistoricalQueries,
+	constants.DefaultProfileName,
+	constants.DefaultSchemaCacheTTLMinutes,
+	constants.DefaultTheme)
+
 func InitConfig(params ConfigParams) error {
 	if !utils.FolderExists(params.ConfigPath, params.StatFunc) {
 		error := params.MkdirFunc(params.ConfigPath, os.ModePerm)
@@ -20,7 +83,9 @@ func InitConfig(params ConfigParams) error {
 		error = createDefaultConfig(params)
 		return error
 	} else {
-		if !utils.FileExists(path.Join(params.ConfigPath, constants.ConfigFileName), params.StatFunc) {
+		// a legacy config is migrated when it is loaded
+		if !utils.FileExists(path.Join(params.ConfigPath, constants.ConfigFileName), params.StatFunc) &&
+			!utils.FileExists(path.Join(params.ConfigPath, constants.LegacyConfigFileName), params.StatFunc) {
 			createDefaultConfig(params)
 		}
 		return nil
@@ -28,73 +93,126 @@ func InitConfig(params ConfigParams) error {
 }
 
 func createDefaultConfig(params ConfigParams) error {
-	defaultConfig := fmt.Appendf([]byte(""), "max_number_historical_queries:%s\nforce_use_neovim:false\ndefault_profile:%s",
-		strconv.FormatInt(int64(constants.DefaultMaxNumberOfHistoricalQueries), 10),
-		constants.DefaultProfileName)
-	err := params.WriteFileFunc(path.Join(params.ConfigPath, constants.ConfigFileName), defaultConfig, 0644)
+	err := params.WriteFileFunc(path.Join(params.ConfigPath, constants.ConfigFileName), []byte(defaultConfig), 0644)
 	if err != nil {
 		return err
 	}
-	err = params.WriteFileFunc(path.Join(params.ConfigPath, constants.ProfilesFileName), []byte(""), 0644)
+	if utils.FileExists(path.Join(params.ConfigPath, constants.ProfilesFileName), params.StatFunc) {
+		return nil
+	}
+	err = params.WriteFileFunc(path.Join(params.ConfigPath, constants.ProfilesFileName), []byte(""), 0600)
 
 	return err
 }
 
-func parseConfigFile(key string, params ConfigParams) (string, error) {
-	fileContents, err := params.ReadFileFunc(path.Join(params.ConfigPath, constants.ConfigFileName))
-	if err != nil {
-		return "", err
-	}
-	fileContentsString := string(fileContents)
-	fileContentsLines := strings.Split(fileContentsString, "\n")
+// ConfigError points at the setting of a config file that can't be used.
+type ConfigError struct {
+	File    string
+	Line    int
+	Message string
+}
 
-	for i := range fileContentsLines {
-		lineSplit := strings.Split(fileContentsLines[i], ":")
-		if key == lineSplit[0] {
-			return lineSplit[1], nil
-		}
+func (e ConfigError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("%s: %s", e.File, e.Message)
 	}
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+}
 
-	return "", fmt.Errorf("No key matches %s", key)
+// Load reads config.yaml, migrating the legacy key:value config to it if it
+// doesn't exist yet, and applies the environment variable overrides.
+func Load(params ConfigParams) (Config, error) {
+	config, err := loadFile(params)
+	if err != nil {
+		return config, err
+	}
+	err = config.Apply(EnvOverrides(params.GetEnvFunc))
+	params.Logger.Debug("CONFIG:", "config", config)
+	return config, err
 }
 
-func GetMaxNumberHistoricalQueries(params ConfigParams) int16 {
-	configValue, error := parseConfigFile("max_number_historical_queries", params)
-	params.Logger.Debug("CONFIG:", "max_number_historical_queries", configValue)
-	if error != nil {
-		return constants.DefaultMaxNumberOfHistoricalQueries
+func loadFile(params ConfigParams) (Config, error) {
+	fileName := path.Join(params.ConfigPath, constants.ConfigFileName)
+	contents, err := params.ReadFileFunc(fileName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return migrateLegacyConfig(params)
 	}
+	if err != nil {
+		return Config{}, err
+	}
+	return Parse(fileName, contents)
+}
 
-	if configValue == "" {
-		return constants.DefaultMaxNumberOfHistoricalQueries
+// Parse decodes and validates the contents of a config file, reporting every
+// unknown setting and bad value with its line.
+func Parse(fileName string, contents []byte) (Config, error) {
+	config := Default()
+	decoder := yaml.NewDecoder(bytes.NewReader(contents))
+	decoder.KnownFields(true)
+	err := decoder.Decode(&config)
+	if errors.Is(err, io.EOF) {
+		return config, nil
+	}
+	var typeError *yaml.TypeError
+	if errors.As(err, &typeError) {
+		errs := make([]error, len(typeError.Errors))
+		for i, message := range typeError.Errors {
+			errs[i] = fmt.Errorf("%s: %s", fileName, message)
+		}
+		return Default(), errors.Join(errs...)
 	}
-	variableAsInt, err := strconv.Atoi(configValue)
 	if err != nil {
-		return constants.DefaultMaxNumberOfHistoricalQueries
-	} else if variableAsInt <= 0 || variableAsInt >= 32767 {
-		return constants.DefaultMaxNumberOfHistoricalQueries
-	} else {
-		return int16(variableAsInt)
+		return Default(), fmt.Errorf("%s: %w", fileName, err)
 	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(contents, &root); err != nil {
+		return Default(), fmt.Errorf("%s: %w", fileName, err)
+	}
+	locate := func(key string) (string, int) { return fileName, keyLine(&root, key) }
+	if errs := config.validate(locate); len(errs) > 0 {
+		return Default(), errors.Join(errs...)
+	}
+	return config, nil
 }
 
-func GetForceUseNeovim(params ConfigParams) bool {
-	configValue, error := parseConfigFile("force_use_neovim", params)
-	params.Logger.Debug("CONFIG:", "force_use_neovim", configValue)
-	if error != nil {
-		return false
+// builtInThemes can be named by the theme setting without being defined.
+var builtInThemes = []string{constants.DefaultTheme, "dark", "light", "high-contrast"}
+
+// validate checks the values decoding can't, finding where a setting was
+// set with locate.
+func (c Config) validate(locate func(key string) (string, int)) []error {
+	var errs []error
+	invalid := func(key string, format string, args ...any) {
+		message := key + ": " + fmt.Sprintf(format, args...)
+		file, line := locate(key)
+		errs = append(errs, ConfigError{file, line, message})
+	}
+	if c.MaxNumberHistoricalQueries <= 0 || c.MaxNumberHistoricalQueries >= 32767 {
+		invalid("max_number_historical_queries", "must be between 1 and 32766, not %d", c.MaxNumberHistoricalQueries)
 	}
-	if configValue == "true" {
-		return true
+	if c.SchemaCacheTTLMinutes < 0 {
+		invalid("schema_cache_ttl_minutes", "must not be negative")
 	}
-	return false
+	if c.DefaultProfile == "" {
+		invalid("default_profile", "must not be empty")
+	}
+	if _, ok := c.Themes[c.Theme]; !ok && !slices.Contains(builtInThemes, c.Theme) {
+		invalid("theme", "unknown theme %q", c.Theme)
+	}
+	return errs
 }
 
-func GetDefaultProfile(params ConfigParams) string {
-	configValue, error := parseConfigFile("default_profile", params)
-	params.Logger.Debug("CONFIG:", "default_profile", configValue)
-	if error != nil {
-		return constants.DefaultProfileName
+// keyLine finds the line of a top level key in a YAML document, or 0.
+func keyLine(root *yaml.Node, key string) int {
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 {
+		return 0
+	}
+	mapping := root.Content[0]
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i].Line
+		}
 	}
-	return configValue
+	return 0
 }
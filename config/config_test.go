@@ -0,0 +1,105 @@
+package config
+
+import (
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"testing"
+
+	"example.com/termquery/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	config, err := Parse("config.yaml", []byte(`
+max_number_historical_queries: 25
+default_profile: "prod:eu"
+theme: mine
+themes:
+  mine:
+    base: light
+keymap:
+  sort: [s, ctrl+s]
+`))
+	assert.Nil(t, err)
+	want := Default()
+	want.MaxNumberHistoricalQueries = 25
+	want.DefaultProfile = "prod:eu"
+	want.Theme = "mine"
+	want.Themes = map[string]map[string]string{"mine": {"base": "light"}}
+	want.Keymap = map[string][]string{"sort": {"s", "ctrl+s"}}
+	assert.Equal(t, want, config)
+}
+
+func TestParseEmpty(t *testing.T) {
+	config, err := Parse("config.yaml", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, Default(), config)
+}
+
+func TestParseErrorsPointAtLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		message  string
+	}{
+		{"unknown setting", "theme: dark\nmax_queries: 3\n", "config.yaml: line 2: field max_queries not found in type config.Config"},
+		{"wrong type", "format_on_save: sometimes\n", "config.yaml: line 1: cannot unmarshal !!str `sometimes` into bool"},
+		{"out of range", "theme: dark\n\nmax_number_historical_queries: 0\n", "config.yaml:3: max_number_historical_queries: must be between 1 and 32766, not 0"},
+		{"unknown theme", "theme: neon\n", `config.yaml:1: theme: unknown theme "neon"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Parse("config.yaml", []byte(test.contents))
+			assert.EqualError(t, err, test.message)
+		})
+	}
+}
+
+func TestLoadMigratesLegacyConfig(t *testing.T) {
+	files := map[string][]byte{
+		path.Join("conf", constants.LegacyConfigFileName): []byte("max_number_historical_queries:20\nforce_use_neovim:true\ndefault_profile:team:eu\nkeymap.quit:Q ctrl+q\ntheme.mine.header:#ffffff\n"),
+	}
+	params := ConfigParams{
+		Logger:     slog.Default(),
+		ConfigPath: "conf",
+		GetEnvFunc: func(string) string { return "" },
+		ReadFileFunc: func(name string) ([]byte, error) {
+			contents, ok := files[name]
+			if !ok {
+				return nil, fs.ErrNotExist
+			}
+			return contents, nil
+		},
+		WriteFileFunc: func(name string, data []byte, perm os.FileMode) error {
+			files[name] = data
+			return nil
+		},
+	}
+
+	config, err := Load(params)
+	assert.Nil(t, err)
+	assert.Equal(t, 20, config.MaxNumberHistoricalQueries)
+	assert.True(t, config.ForceUseNeovim)
+	assert.Equal(t, "team:eu", config.DefaultProfile)
+	assert.Equal(t, []string{"Q", "ctrl+q"}, config.Keymap["quit"])
+	assert.Equal(t, "#ffffff", config.Themes["mine"]["header"])
+
+	// from now on the migrated file is read
+	assert.Contains(t, files, path.Join("conf", constants.ConfigFileName))
+	reloaded, err := Load(params)
+	assert.Nil(t, err)
+	assert.Equal(t, config, reloaded)
+}
+
+func TestLegacyConfigErrors(t *testing.T) {
+	_, err := parseLegacyConfig("config", []byte("force_use_neovim:yes\n\nmax_history:3\n"))
+	assert.EqualError(t, err, "config:1: force_use_neovim: expected true or false, not \"yes\"\nconfig:3: unknown setting \"max_history\"")
+}
+
+func TestDefaultConfigParses(t *testing.T) {
+	config, err := Parse("config.yaml", []byte(defaultConfig))
+	assert.Nil(t, err)
+	assert.Equal(t, Default(), config)
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+
+	"example.com/termquery/utils"
+)
+
+// DatabricksConfigPath is where the Databricks CLI keeps its profiles, which
+// DATABRICKS_CONFIG_FILE moves.
+func DatabricksConfigPath(home string, getenv utils.GetEnvFunc) string {
+	if path := getenv("DATABRICKS_CONFIG_FILE"); path != "" {
+		return path
+	}
+	return filepath.Join(home, ".databrickscfg")
+}
+
+// databricksAuthTypes maps the auth types of the Databricks CLI to the ones
+// of a profile. Logging in through the CLI is a browser login.
+var databricksAuthTypes = map[string]string{
+	"":                 "",
+	"pat":              "pat",
+	"oauth-m2m":        "oauth-m2m",
+	"databricks-cli":   "oauth-u2m",
+	"external-browser": "oauth-u2m",
+}
+
+// LoadDatabricksProfiles reads the profiles of the Databricks CLI, in file
+// order. A missing file has no profiles.
+func LoadDatabricksProfiles(params ConfigParams) ([]Profile, error) {
+	sections, err := readDatabricksConfig(params)
+	if err != nil {
+		return nil, err
+	}
+	profiles := make([]Profile, len(sections))
+	for i, section := range sections {
+		profiles[i] = databricksProfile(section)
+	}
+	return profiles, nil
+}
+
+// readDatabricksConfig parses the Databricks CLI config as leniently as the
+// CLI itself. The file is read whenever a profile isn't in termquery's own,
+// so a section termquery doesn't use mustn't keep it from starting.
+func readDatabricksConfig(params ConfigParams) ([]iniSection, error) {
+	if params.DatabricksConfigPath == "" {
+		return nil, nil
+	}
+	contents, err := params.ReadFileFunc(params.DatabricksConfigPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseLenientINI(params.DatabricksConfigPath, contents), nil
+}
+
+// databricksProfile takes the connection settings from a profile of the
+// Databricks CLI. The CLI knows many more settings, which are skipped, so an
+// auth type termquery doesn't support only fails once the profile is used.
+func databricksProfile(section iniSection) Profile {
+	profile := Profile{Name: section.Name}
+	for _, s := range section.Settings {
+		switch s.Key {
+		case "host":
+			profile.ServerHostname = hostname(s.Value)
+		case "token":
+			profile.AccessToken = s.Value
+		case "http_path":
+			profile.HttpPath = s.Value
+		case "warehouse_id":
+			// an explicit http_path wins, wherever it is in the section
+			if profile.HttpPath == "" {
+				profile.HttpPath = "/sql/1.0/warehouses/" + s.Value
+			}
+		case "client_id":
+			profile.ClientID = s.Value
+		case "client_secret":
+			profile.ClientSecret = s.Value
+		case "auth_type":
+			authType, ok := databricksAuthTypes[s.Value]
+			if !ok {
+				authType = s.Value
+			}
+			profile.AuthType = authType
+		}
+	}
+	return profile
+}
+
+// findDatabricksProfile looks a profile up in the Databricks CLI config. Only
+// the problems of that profile's section are reported.
+func findDatabricksProfile(params ConfigParams, name string) (Profile, bool, error) {
+	sections, err := readDatabricksConfig(params)
+	if err != nil {
+		return Profile{}, false, err
+	}
+	for _, section := range sections {
+		if section.Name != name {
+			continue
+		}
+		if section.Err != nil {
+			return Profile{}, false, section.Err
+		}
+		p := databricksProfile(section)
+		if err := p.validateAuth(); err != nil {
+			return Profile{}, false, fmt.Errorf("%s: [%s]: %w", params.DatabricksConfigPath, name, err)
+		}
+		return p, true, nil
+	}
+	return Profile{}, false, nil
+}
+
+// inherit fills the settings p leaves out from the Databricks CLI profile it
+// refers to. The credentials are only taken as a whole.
+func (p *Profile) inherit(base Profile) {
+	if p.ServerHostname == "" {
+		p.ServerHostname = base.ServerHostname
+	}
+	if p.HttpPath == "" {
+		p.HttpPath = base.HttpPath
+	}
+	settings := p.profileSettings()
+	for _, key := range append([]string{"auth_type", "client_id", "client_secret"}, tokenKeys...) {
+		if *settings[key] != "" {
+			return
+		}
+	}
+	p.AuthType, p.ClientID, p.ClientSecret, p.AccessToken = base.AuthType, base.ClientID, base.ClientSecret, base.AccessToken
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"path"
+	"testing"
+
+	"example.com/termquery/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+const databricksConfig = `; written by the Databricks CLI
+[DEFAULT]
+host = https://adb-1.azuredatabricks.net/
+token = dapi-default
+warehouse_id = abc123
+cluster_id = 0101-abc
+
+[sp]
+host = https://adb-2.azuredatabricks.net
+auth_type = oauth-m2m
+client_id = principal
+client_secret = secret
+http_path = /sql/1.0/warehouses/explicit
+warehouse_id = ignored
+
+[login]
+host = https://dbc-1.cloud.databricks.com
+auth_type = databricks-cli
+`
+
+func TestLoadDatabricksProfiles(t *testing.T) {
+	params := memoryProfileParams(map[string][]byte{".databrickscfg": []byte(databricksConfig)}, nil)
+	params.DatabricksConfigPath = ".databrickscfg"
+	profiles, err := LoadDatabricksProfiles(params)
+	assert.Nil(t, err)
+	assert.Equal(t, []Profile{
+		{Name: "DEFAULT", ServerHostname: "adb-1.azuredatabricks.net", HttpPath: "/sql/1.0/warehouses/abc123", AccessToken: "dapi-default"},
+		{Name: "sp", ServerHostname: "adb-2.azuredatabricks.net", HttpPath: "/sql/1.0/warehouses/explicit", AuthType: "oauth-m2m", ClientID: "principal", ClientSecret: "secret"},
+		{Name: "login", ServerHostname: "dbc-1.cloud.databricks.com", AuthType: "oauth-u2m"},
+	}, profiles)
+}
+
+func TestGetProfileFromDatabricksConfig(t *testing.T) {
+	files := map[string][]byte{
+		"home/.databrickscfg": []byte(databricksConfig),
+		path.Join("conf", constants.ProfilesFileName): []byte(`[DEFAULT]
+server_hostname = mine.net
+http_path = /sql/mine
+access_token = mine
+[login-wh]
+databrickscfg_profile = login
+http_path = /sql/1.0/warehouses/w
+[token-of-my-own]
+databrickscfg_profile = DEFAULT
+access_token_env = MY_TOKEN
+[dangling]
+databrickscfg_profile = gone
+`),
+	}
+	params := memoryProfileParams(files, map[string]string{"MY_TOKEN": "from-env"})
+	params.DatabricksConfigPath = "home/.databrickscfg"
+
+	// the profiles file comes first
+	profile, err := GetProfile(params, "DEFAULT")
+	assert.Nil(t, err)
+	assert.Equal(t, "mine.net", profile.ServerHostname)
+
+	profile, err = GetProfile(params, "sp")
+	assert.Nil(t, err)
+	assert.Equal(t, Profile{Name: "sp", ServerHostname: "adb-2.azuredatabricks.net", HttpPath: "/sql/1.0/warehouses/explicit", AuthType: "oauth-m2m", ClientID: "principal", ClientSecret: "secret"}, profile)
+
+	_, err = GetProfile(params, "login")
+	assert.EqualError(t, err, "home/.databrickscfg: [login] has no warehouse_id or http_path, add one there or to a profile with databrickscfg_profile = login")
+
+	profile, err = GetProfile(params, "login-wh")
+	assert.Nil(t, err)
+	assert.Equal(t, Profile{Name: "login-wh", ServerHostname: "dbc-1.cloud.databricks.com", HttpPath: "/sql/1.0/warehouses/w", AuthType: "oauth-u2m", DatabricksProfile: "login"}, profile)
+
+	// credentials of the profile's own aren't mixed with the referenced ones
+	profile, err = GetProfile(params, "token-of-my-own")
+	assert.Nil(t, err)
+	assert.Equal(t, "adb-1.azuredatabricks.net", profile.ServerHostname)
+	assert.Equal(t, "from-env", profile.AccessToken)
+
+	_, err = GetProfile(params, "dangling")
+	assert.EqualError(t, err, "profile dangling: databrickscfg_profile gone not in home/.databrickscfg")
+	_, err = GetProfile(params, "nope")
+	assert.EqualError(t, err, "profile nope not in conf/profiles or home/.databrickscfg")
+}
+
+func TestParseLenientINI(t *testing.T) {
+	sections := parseLenientINI(".databrickscfg", []byte(`host = https://top.net
+[dev]
+host = https://old.net
+a line that isn't a setting
+token = "unclosed
+[dev]
+host = https://new.net
+[broken
+host = https://broken.net
+[]
+host = https://nowhere.net
+`))
+	assert.Equal(t, []iniSection{
+		{Name: "DEFAULT", Line: 1, Settings: []iniSetting{{"host", "https://top.net", 1}}},
+		{Name: "dev", Line: 2, Settings: []iniSetting{{"host", "https://new.net", 7}},
+			Err: ConfigError{".databrickscfg", 5, "token: missing closing quote"}},
+		{Name: "broken", Line: 8, Settings: []iniSetting{{"host", "https://broken.net", 9}},
+			Err: ConfigError{".databrickscfg", 8, `expected [section], not "[broken"`}},
+	}, sections)
+}
+
+func TestGetProfileSkipsBrokenDatabricksSections(t *testing.T) {
+	files := map[string][]byte{"home/.databrickscfg": []byte(databricksConfig + `
+[broken]
+token = "unclosed
+`)}
+	env := map[string]string{
+		"DATABRICKS_HOST":      "env.net",
+		"DATABRICKS_HTTP_PATH": "/sql/env",
+		"DATABRICKS_TOKEN":     "env",
+	}
+	params := memoryProfileParams(files, env)
+	params.DatabricksConfigPath = "home/.databrickscfg"
+
+	profile, err := GetProfile(params, "dev")
+	assert.Nil(t, err)
+	assert.Equal(t, Profile{Name: "dev", ServerHostname: "env.net", HttpPath: "/sql/env", AccessToken: "env"}, profile)
+
+	_, err = GetProfile(params, "broken")
+	assert.EqualError(t, err, "home/.databrickscfg:21: token: missing closing quote")
+}
+
+func TestDatabricksConfigPath(t *testing.T) {
+	assert.Equal(t, "/home/me/.databrickscfg", DatabricksConfigPath("/home/me", func(string) string { return "" }))
+	assert.Equal(t, "/etc/dbcfg", DatabricksConfigPath("/home/me", func(string) string { return "/etc/dbcfg" }))
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"example.com/termquery/constants"
+	"example.com/termquery/utils"
+)
+
+// Settings are resolved in this order, each overriding the ones before it:
+//
+//  1. the defaults
+//  2. config.yaml
+//  3. environment variables
+//  4. command line flags
+//
+// Profiles follow the same order, with DATABRICKS_HOST, DATABRICKS_TOKEN and
+// DATABRICKS_HTTP_PATH overriding the values from the profiles file.
+
+// envSettings are the environment variables that override a setting.
+var envSettings = []struct {
+	variable string
+	key      string
+}{
+	{"TERMQUERY_PROFILE", "default_profile"},
+	{"TERMQUERY_MAX_HISTORY", "max_number_historical_queries"},
+}
+
+const (
+	hostVariable     = "DATABRICKS_HOST"
+	tokenVariable    = "DATABRICKS_TOKEN"
+	httpPathVariable = "DATABRICKS_HTTP_PATH"
+)
+
+// EnvOverrides lists the settings overridden by environment variables.
+func EnvOverrides(getenv utils.GetEnvFunc) []Setting {
+	var overrides []Setting
+	for _, e := range envSettings {
+		if value := getenv(e.variable); value != "" {
+			overrides = append(overrides, Setting{Key: e.key, Value: value, Source: SourceEnv, Origin: e.variable})
+		}
+	}
+	return overrides
+}
+
+// Apply sets the overridden settings, reporting a bad value by where it came
+// from. Only the settings with a single value can be overridden.
+func (c *Config) Apply(overrides []Setting) error {
+	origins := map[string]string{}
+	for _, o := range overrides {
+		if err := c.set(o.Key, o.Value); err != nil {
+			return ConfigError{File: o.Origin, Message: fmt.Sprintf("%s: %s", o.Key, err)}
+		}
+		origins[o.Key] = o.Origin
+	}
+	errs := c.validate(func(key string) (string, int) {
+		if origin, ok := origins[key]; ok {
+			return origin, 0
+		}
+		return constants.ConfigFileName, 0
+	})
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+func (c *Config) set(key string, value string) error {
+	number := func(field *int) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("expected a number, not %q", value)
+		}
+		*field = n
+		return nil
+	}
+	boolean := func(field *bool) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("expected true or false, not %q", value)
+		}
+		*field = b
+		return nil
+	}
+	switch key {
+	case "max_number_historical_queries":
+		return number(&c.MaxNumberHistoricalQueries)
+	case "schema_cache_ttl_minutes":
+		return number(&c.SchemaCacheTTLMinutes)
+	case "force_use_neovim":
+		return boolean(&c.ForceUseNeovim)
+	case "format_on_save":
+		return boolean(&c.FormatOnSave)
+	case "default_profile":
+		c.DefaultProfile = value
+	case "theme":
+		c.Theme = value
+	default:
+		return fmt.Errorf("can't be overridden")
+	}
+	return nil
+}
+
+// envProfile is the profile described by the DATABRICKS_ variables. Any of
+// its values may be empty.
+func envProfile(getenv utils.GetEnvFunc) Profile {
+	return Profile{
+		ServerHostname: hostname(getenv(hostVariable)),
+		HttpPath:       getenv(httpPathVariable),
+		AccessToken:    getenv(tokenVariable),
+	}
+}
+
+// hostname strips the scheme and trailing slash from a workspace URL, which
+// is how the Databricks CLI writes DATABRICKS_HOST.
+func hostname(host string) string {
+	host = strings.TrimPrefix(host, "https://")
+	host = strings.TrimPrefix(host, "http://")
+	return strings.TrimSuffix(host, "/")
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"log/slog"
+	"path"
+	"testing"
+
+	"example.com/termquery/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadAppliesEnvOverrides(t *testing.T) {
+	files := map[string][]byte{path.Join("conf", constants.ConfigFileName): []byte("default_profile: dev\nmax_number_historical_queries: 5\n")}
+	env := map[string]string{"TERMQUERY_PROFILE": "prod", "TERMQUERY_MAX_HISTORY": "50"}
+	params := memoryProfileParams(files, env)
+	params.Logger = slog.Default()
+
+	config, err := Load(params)
+	assert.Nil(t, err)
+	assert.Equal(t, "prod", config.DefaultProfile)
+	assert.Equal(t, 50, config.MaxNumberHistoricalQueries)
+
+	settings, err := Settings(params, config, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, Setting{"max_number_historical_queries", "50", SourceEnv, "TERMQUERY_MAX_HISTORY"}, settings[0])
+
+	env["TERMQUERY_MAX_HISTORY"] = "lots"
+	_, err = Load(params)
+	assert.EqualError(t, err, `TERMQUERY_MAX_HISTORY: max_number_historical_queries: expected a number, not "lots"`)
+	env["TERMQUERY_MAX_HISTORY"] = "0"
+	_, err = Load(params)
+	assert.EqualError(t, err, "TERMQUERY_MAX_HISTORY: max_number_historical_queries: must be between 1 and 32766, not 0")
+}
+
+func TestGetProfileEnvOverrides(t *testing.T) {
+	files := map[string][]byte{path.Join("conf", constants.ProfilesFileName): []byte("[dev]\nserver_hostname = dev.net\nhttp_path = /sql/dev\naccess_token = file\n")}
+	env := map[string]string{"DATABRICKS_TOKEN": "env"}
+	params := memoryProfileParams(files, env)
+
+	profile, err := GetProfile(params, "dev")
+	assert.Nil(t, err)
+	assert.Equal(t, Profile{Name: "dev", ServerHostname: "dev.net", HttpPath: "/sql/dev", AccessToken: "env"}, profile)
+
+	// without every value from the environment, the profile has to exist
+	_, err = GetProfile(params, "prod")
+	assert.EqualError(t, err, "profile prod not in conf/profiles")
+
+	env["DATABRICKS_HOST"] = "https://prod.net/"
+	env["DATABRICKS_HTTP_PATH"] = "/sql/prod"
+	profile, err = GetProfile(memoryProfileParams(map[string][]byte{}, env), "prod")
+	assert.Nil(t, err)
+	assert.Equal(t, Profile{Name: "prod", ServerHostname: "prod.net", HttpPath: "/sql/prod", AccessToken: "env"}, profile)
+}
@@ -0,0 +1,240 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// iniSetting is one key and value of an INI file.
+type iniSetting struct {
+	Key   string
+	Value string
+	Line  int
+}
+
+// iniSection is a [name] section with its settings in file order.
+type iniSection struct {
+	Name     string
+	Line     int
+	Settings []iniSetting
+	Err      error // the first line of the section parseLenientINI couldn't read
+}
+
+// parseINI reads [section] headers and key = value (or key: value) settings.
+// Lines starting with # or ; are comments, as is anything after " #" or " ;"
+// in an unquoted value. Values may be quoted to keep surrounding spaces or
+// comment characters.
+func parseINI(fileName string, contents []byte) ([]iniSection, error) {
+	var sections []iniSection
+	invalid := func(line int, format string, args ...any) error {
+		return ConfigError{fileName, line, fmt.Sprintf(format, args...)}
+	}
+	for i, line := range strings.Split(string(contents), "\n") {
+		number := i + 1
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			name, ok := strings.CutSuffix(line, "]")
+			name = strings.TrimSpace(strings.TrimPrefix(name, "["))
+			if !ok || name == "" {
+				return nil, invalid(number, "expected [section], not %q", line)
+			}
+			for _, s := range sections {
+				if s.Name == name {
+					return nil, invalid(number, "section [%s] is already defined on line %d", name, s.Line)
+				}
+			}
+			sections = append(sections, iniSection{Name: name, Line: number})
+			continue
+		}
+
+		separator := strings.IndexAny(line, "=:")
+		if separator <= 0 {
+			return nil, invalid(number, "expected key = value, not %q", line)
+		}
+		if len(sections) == 0 {
+			return nil, invalid(number, "setting outside of a [section]")
+		}
+		key := strings.TrimSpace(line[:separator])
+		value, err := iniValue(strings.TrimSpace(line[separator+1:]))
+		if err != nil {
+			return nil, invalid(number, "%s: %s", key, err)
+		}
+		section := &sections[len(sections)-1]
+		for _, s := range section.Settings {
+			if s.Key == key {
+				return nil, invalid(number, "%s is already set on line %d", key, s.Line)
+			}
+		}
+		section.Settings = append(section.Settings, iniSetting{key, value, number})
+	}
+	return sections, nil
+}
+
+// parseLenientINI reads an INI file written by another tool the way that tool
+// does: a section or key given twice takes the last value, settings above the
+// first header belong to [DEFAULT] and lines that aren't settings are
+// skipped. A header or value that can't be read is left out and recorded in
+// Err of its section, so it only matters once that section is used.
+func parseLenientINI(fileName string, contents []byte) []iniSection {
+	var sections []iniSection
+	current, headers := -1, false
+	section := func(name string, line int) int {
+		for i := range sections {
+			if sections[i].Name == name {
+				return i
+			}
+		}
+		sections = append(sections, iniSection{Name: name, Line: line})
+		return len(sections) - 1
+	}
+	invalid := func(line int, format string, args ...any) {
+		if current >= 0 && sections[current].Err == nil {
+			sections[current].Err = ConfigError{fileName, line, fmt.Sprintf(format, args...)}
+		}
+	}
+	for i, line := range strings.Split(string(contents), "\n") {
+		number := i + 1
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			headers = true
+			name, ok := strings.CutSuffix(line, "]")
+			name = strings.TrimSpace(strings.TrimPrefix(name, "["))
+			if name == "" {
+				// the settings below belong to no section
+				current = -1
+				continue
+			}
+			current = section(name, number)
+			if !ok {
+				invalid(number, "expected [section], not %q", line)
+			}
+			continue
+		}
+
+		separator := strings.IndexAny(line, "=:")
+		if separator <= 0 {
+			continue
+		}
+		if !headers {
+			current = section("DEFAULT", number)
+		}
+		if current < 0 {
+			continue
+		}
+		key := strings.TrimSpace(line[:separator])
+		value, err := iniValue(strings.TrimSpace(line[separator+1:]))
+		if err != nil {
+			invalid(number, "%s: %s", key, err)
+			continue
+		}
+		settings := &sections[current].Settings
+		index := slices.IndexFunc(*settings, func(s iniSetting) bool { return s.Key == key })
+		if index >= 0 {
+			(*settings)[index] = iniSetting{key, value, number}
+		} else {
+			*settings = append(*settings, iniSetting{key, value, number})
+		}
+	}
+	return sections
+}
+
+// iniValue unquotes a value, or strips a trailing comment from an unquoted one.
+func iniValue(raw string) (string, error) {
+	if raw == "" {
+		return "", nil
+	}
+	switch raw[0] {
+	case '"':
+		end := closingQuote(raw)
+		if end < 0 {
+			return "", fmt.Errorf("missing closing quote")
+		}
+		if err := trailingComment(raw[end+1:]); err != nil {
+			return "", err
+		}
+		return strconv.Unquote(raw[:end+1])
+	case '\'':
+		end := strings.IndexByte(raw[1:], '\'')
+		if end < 0 {
+			return "", fmt.Errorf("missing closing quote")
+		}
+		if err := trailingComment(raw[end+2:]); err != nil {
+			return "", err
+		}
+		return raw[1 : end+1], nil
+	}
+	for _, marker := range []string{" #", "\t#", " ;", "\t;"} {
+		if i := strings.Index(raw, marker); i >= 0 {
+			raw = raw[:i]
+		}
+	}
+	return strings.TrimSpace(raw), nil
+}
+
+// closingQuote finds the quote ending a double quoted value, skipping escapes.
+func closingQuote(raw string) int {
+	for i := 1; i < len(raw); i++ {
+		switch raw[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+func trailingComment(rest string) error {
+	rest = strings.TrimSpace(rest)
+	if rest == "" || strings.HasPrefix(rest, "#") || strings.HasPrefix(rest, ";") {
+		return nil
+	}
+	return fmt.Errorf("unexpected %q after the quoted value", rest)
+}
+
+// formatINIValue quotes a value that would not read back unchanged otherwise.
+func formatINIValue(value string) string {
+	if value != strings.TrimSpace(value) || strings.ContainsAny(value, "#;\"'\n") {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+// removeINISection cuts a section out of contents, along with the comments
+// directly above its header. Comments above the next header are kept, as
+// they describe that section.
+func removeINISection(contents []byte, sections []iniSection, name string) ([]byte, bool) {
+	lines := strings.SplitAfter(string(contents), "\n")
+	isComment := func(line string) bool {
+		line = strings.TrimSpace(line)
+		return strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";")
+	}
+	for i, section := range sections {
+		if section.Name != name {
+			continue
+		}
+		// lines are numbered from 1
+		start, end := section.Line-1, len(lines)
+		if i+1 < len(sections) {
+			end = sections[i+1].Line - 1
+			for end > start+1 && (isComment(lines[end-1]) || strings.TrimSpace(lines[end-1]) == "") {
+				end--
+			}
+		}
+		for start > 0 && isComment(lines[start-1]) {
+			start--
+		}
+		return []byte(strings.Join(append(lines[:start:start], lines[end:]...), "")), true
+	}
+	return contents, false
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+
+	"example.com/termquery/constants"
+
+	"gopkg.in/yaml.v3"
+)
+
+// migrateLegacyConfig reads the key:value config used before config.yaml and
+// writes its settings to config.yaml. The legacy file is left in place.
+func migrateLegacyConfig(params ConfigParams) (Config, error) {
+	fileName := path.Join(params.ConfigPath, constants.LegacyConfigFileName)
+	contents, err := params.ReadFileFunc(fileName)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Default(), nil
+	}
+	if err != nil {
+		return Config{}, err
+	}
+	config, err := parseLegacyConfig(fileName, contents)
+	if err != nil {
+		return Default(), err
+	}
+
+	migrated, err := yaml.Marshal(config)
+	if err != nil {
+		return config, err
+	}
+	header := fmt.Sprintf("# termquery configuration, migrated from %s\n\n", fileName)
+	err = params.WriteFileFunc(path.Join(params.ConfigPath, constants.ConfigFileName), append([]byte(header), migrated...), 0644)
+	if err != nil {
+		params.Logger.Error("Could not write the migrated config", "error", err)
+	} else {
+		params.Logger.Info("Migrated config", "from", fileName, "to", constants.ConfigFileName)
+	}
+	return config, nil
+}
+
+// parseLegacyConfig reads lines of key:value. Only the first colon ends the
+// key, so values may contain colons.
+func parseLegacyConfig(fileName string, contents []byte) (Config, error) {
+	config := Default()
+	lines := map[string]int{}
+	var errs []error
+	for i, line := range strings.Split(string(contents), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			errs = append(errs, ConfigError{fileName, i + 1, "expected key:value"})
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		lines[key] = i + 1
+		invalid := func(expected string) {
+			errs = append(errs, ConfigError{fileName, i + 1, fmt.Sprintf("%s: expected %s, not %q", key, expected, value)})
+		}
+
+		switch key {
+		case "max_number_historical_queries":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				invalid("a number")
+			}
+			config.MaxNumberHistoricalQueries = n
+		case "schema_cache_ttl_minutes":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				invalid("a number")
+			}
+			config.SchemaCacheTTLMinutes = n
+		case "force_use_neovim", "format_on_save":
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				invalid("true or false")
+			}
+			if key == "force_use_neovim" {
+				config.ForceUseNeovim = b
+			} else {
+				config.FormatOnSave = b
+			}
+		case "default_profile":
+			config.DefaultProfile = value
+		case "theme":
+			config.Theme = value
+		default:
+			if name, ok := strings.CutPrefix(key, "theme."); ok {
+				theme, colour, ok := strings.Cut(name, ".")
+				if !ok {
+					invalid("theme.<name>.<colour>")
+					continue
+				}
+				if config.Themes == nil {
+					config.Themes = map[string]map[string]string{}
+				}
+				if config.Themes[theme] == nil {
+					config.Themes[theme] = map[string]string{}
+				}
+				config.Themes[theme][colour] = value
+			} else if action, ok := strings.CutPrefix(key, "keymap."); ok {
+				// keys were separated by spaces, so the space bar had a name
+				keys := strings.Fields(value)
+				for j, k := range keys {
+					if k == "space" {
+						keys[j] = " "
+					}
+				}
+				if config.Keymap == nil {
+					config.Keymap = map[string][]string{}
+				}
+				config.Keymap[action] = keys
+			} else {
+				errs = append(errs, ConfigError{fileName, i + 1, fmt.Sprintf("unknown setting %q", key)})
+			}
+		}
+	}
+	if len(errs) == 0 {
+		errs = config.validate(func(key string) (string, int) { return fileName, lines[key] })
+	}
+	return config, errors.Join(errs...)
+}
@@ -1,60 +1,230 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"path"
+	"slices"
 	"strings"
 
 	"example.com/termquery/constants"
 )
 
-func readProfile(params ConfigParams, profileName string) ([]string, error) {
-	fileContents, err := params.ReadFileFunc(path.Join(params.ConfigPath, constants.ProfilesFileName))
+// Profile is a connection to a Databricks SQL warehouse. The access token
+// of the pat auth type is either written out or read from one of the other
+// AccessToken settings.
+type Profile struct {
+	Name            string
+	ServerHostname  string
+	HttpPath        string
+	AccessToken     string
+	AccessTokenCmd  string // a shell command printing the token
+	AccessTokenFile string // a file holding the token
+	AccessTokenEnv  string // an environment variable holding the token
+	AuthType        string // pat, oauth-m2m or oauth-u2m, pat if empty
+	ClientID        string // the service principal of oauth-m2m
+	ClientSecret    string
+	// DatabricksProfile names a profile of the Databricks CLI to take the
+	// settings left out from
+	DatabricksProfile string
+}
+
+// profileKeys are the settings of a profile, in the order they are written.
+var profileKeys = []string{"server_hostname", "http_path", "auth_type", "access_token", "access_token_cmd", "access_token_file", "access_token_env", "client_id", "client_secret", "databrickscfg_profile"}
+
+// tokenKeys are the ways of giving a profile its access token.
+var tokenKeys = profileKeys[3:7]
 
-	if !strings.Contains(string(fileContents), "["+profileName+"]") {
-		return []string{""}, fmt.Errorf("Profile %s not in profiles", profileName)
+var authTypes = []string{"pat", "oauth-m2m", "oauth-u2m"}
+
+// profileSettings names the settings of a profile as written in the profiles file.
+func (p *Profile) profileSettings() map[string]*string {
+	return map[string]*string{
+		"server_hostname":       &p.ServerHostname,
+		"http_path":             &p.HttpPath,
+		"access_token":          &p.AccessToken,
+		"access_token_cmd":      &p.AccessTokenCmd,
+		"access_token_file":     &p.AccessTokenFile,
+		"access_token_env":      &p.AccessTokenEnv,
+		"auth_type":             &p.AuthType,
+		"client_id":             &p.ClientID,
+		"client_secret":         &p.ClientSecret,
+		"databrickscfg_profile": &p.DatabricksProfile,
 	}
+}
+
+// LoadProfiles reads every profile from the profiles file, in file order.
+func LoadProfiles(params ConfigParams) ([]Profile, error) {
+	fileName := path.Join(params.ConfigPath, constants.ProfilesFileName)
+	contents, err := params.ReadFileFunc(fileName)
 	if err != nil {
-		return []string{""}, err
+		return nil, err
 	}
+	return parseProfiles(fileName, contents)
+}
 
-	profileStrings := strings.Split(strings.Split(strings.Split(string(fileContents), "["+profileName+"]")[1], "[")[0], "\n")
-	return profileStrings, nil
+func parseProfiles(fileName string, contents []byte) ([]Profile, error) {
+	sections, err := parseINI(fileName, contents)
+	if err != nil {
+		return nil, err
+	}
+	profiles := make([]Profile, len(sections))
+	for i, section := range sections {
+		profile := Profile{Name: section.Name}
+		settings := profile.profileSettings()
+		token := ""
+		for _, s := range section.Settings {
+			value, ok := settings[s.Key]
+			if !ok {
+				return nil, ConfigError{fileName, s.Line, fmt.Sprintf("[%s]: unknown setting %q", section.Name, s.Key)}
+			}
+			if slices.Contains(tokenKeys, s.Key) {
+				if token != "" {
+					return nil, ConfigError{fileName, s.Line, fmt.Sprintf("[%s]: %s and %s can't both be set", section.Name, token, s.Key)}
+				}
+				token = s.Key
+			}
+			*value = s.Value
+		}
+		if err := profile.validateAuth(); err != nil {
+			return nil, ConfigError{fileName, section.Line, fmt.Sprintf("[%s]: %s", section.Name, err)}
+		}
+		profiles[i] = profile
+	}
+	return profiles, nil
 }
 
-func extractSettings(profileStrings []string, settingsName string) (string, error) {
-	for i := range profileStrings {
-		if strings.Contains(profileStrings[i], settingsName) {
-			settingSplit := strings.Split(profileStrings[i], ":")
-			return settingSplit[1], nil
+// GetProfile reads the profile called name from the profiles file, or else
+// from the profiles of the Databricks CLI, with its values overridden by
+// DATABRICKS_HOST, DATABRICKS_TOKEN and DATABRICKS_HTTP_PATH. When all three
+// are set the profile needn't exist.
+func GetProfile(params ConfigParams, name string) (Profile, error) {
+	profiles, err := LoadProfiles(params)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return Profile{}, err
+	}
+	env := envProfile(params.GetEnvFunc)
+	profile, found := Profile{}, false
+	for _, p := range profiles {
+		if p.Name == name {
+			profile, found = p, true
+		}
+	}
+
+	if found && profile.DatabricksProfile != "" {
+		base, ok, err := findDatabricksProfile(params, profile.DatabricksProfile)
+		if err != nil {
+			return Profile{}, err
+		}
+		if !ok {
+			return Profile{}, fmt.Errorf("profile %s: databrickscfg_profile %s not in %s", name, profile.DatabricksProfile, params.DatabricksConfigPath)
+		}
+		profile.inherit(base)
+	} else if !found {
+		profile, found, err = findDatabricksProfile(params, name)
+		if err != nil {
+			return Profile{}, err
+		}
+		if found && profile.HttpPath == "" && env.HttpPath == "" {
+			return Profile{}, fmt.Errorf("%s: [%s] has no warehouse_id or http_path, add one there or to a profile with databrickscfg_profile = %s", params.DatabricksConfigPath, name, name)
+		}
+	}
+
+	if !found && (env.ServerHostname == "" || env.HttpPath == "" || env.AccessToken == "") {
+		fileName := path.Join(params.ConfigPath, constants.ProfilesFileName)
+		if params.DatabricksConfigPath != "" {
+			return Profile{}, fmt.Errorf("profile %s not in %s or %s", name, fileName, params.DatabricksConfigPath)
 		}
+		return Profile{}, fmt.Errorf("profile %s not in %s", name, fileName)
 	}
-	return "", fmt.Errorf("No setting matching %s found", settingsName)
+	profile.Name = name
+	for key, value := range env.profileSettings() {
+		if *value != "" {
+			*profile.profileSettings()[key] = *value
+		}
+	}
+	if profile.AccessToken != "" || !profile.usesToken() {
+		return profile, nil
+	}
+	profile.AccessToken, err = accessToken(params, profile)
+	return profile, err
 }
 
-func GetHttpPath(params ConfigParams, profileName string) (string, error) {
-	profileString, err := readProfile(params, profileName)
+// AddProfile appends a profile to the profiles file, creating the file if
+// needed. The rest of the file is left as it is.
+func AddProfile(params ConfigParams, profile Profile) error {
+	if profile.Name == "" || strings.ContainsAny(profile.Name, "[]\n") || profile.Name != strings.TrimSpace(profile.Name) {
+		return fmt.Errorf("invalid profile name %q", profile.Name)
+	}
+	fileName := path.Join(params.ConfigPath, constants.ProfilesFileName)
+	contents, err := params.ReadFileFunc(fileName)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	profiles, err := parseProfiles(fileName, contents)
 	if err != nil {
-		return "", err
+		return err
 	}
-	settingValue, err := extractSettings(profileString, "http_path")
-	return settingValue, err
+	for _, p := range profiles {
+		if p.Name == profile.Name {
+			return fmt.Errorf("profile %s already exists", profile.Name)
+		}
+	}
+
+	var b strings.Builder
+	b.Write(contents)
+	if len(contents) > 0 {
+		if !strings.HasSuffix(string(contents), "\n") {
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "[%s]\n", profile.Name)
+	settings := profile.profileSettings()
+	for _, key := range profileKeys {
+		if value := *settings[key]; value != "" {
+			fmt.Fprintf(&b, "%s = %s\n", key, formatINIValue(value))
+		}
+	}
+	return params.WriteFileFunc(fileName, []byte(b.String()), 0600)
 }
 
-func GetToken(params ConfigParams, profileName string) (string, error) {
-	profileString, err := readProfile(params, profileName)
+// RemoveProfile deletes a profile from the profiles file.
+func RemoveProfile(params ConfigParams, name string) error {
+	fileName := path.Join(params.ConfigPath, constants.ProfilesFileName)
+	contents, err := params.ReadFileFunc(fileName)
+	if err != nil {
+		return err
+	}
+	sections, err := parseINI(fileName, contents)
 	if err != nil {
-		return "", err
+		return err
 	}
-	settingValue, err := extractSettings(profileString, "access_token")
-	return settingValue, err
+	contents, ok := removeINISection(contents, sections, name)
+	if !ok {
+		return fmt.Errorf("profile %s not in %s", name, fileName)
+	}
+	return params.WriteFileFunc(fileName, contents, 0600)
 }
 
-func GetServerHostname(params ConfigParams, profileName string) (string, error) {
-	profileString, err := readProfile(params, profileName)
-	if err != nil {
-		return "", err
+func (p Profile) usesToken() bool {
+	return p.AuthType == "" || p.AuthType == "pat"
+}
+
+// validateAuth checks that a profile has what its auth type needs.
+func (p Profile) validateAuth() error {
+	if p.AuthType != "" && !slices.Contains(authTypes, p.AuthType) {
+		return fmt.Errorf("auth_type must be one of %s, not %q", strings.Join(authTypes, ", "), p.AuthType)
+	}
+	if p.AuthType == "oauth-m2m" && (p.ClientID == "" || p.ClientSecret == "") {
+		return fmt.Errorf("oauth-m2m needs a client_id and client_secret")
+	}
+	settings := p.profileSettings()
+	for _, key := range tokenKeys {
+		if *settings[key] != "" && !p.usesToken() {
+			return fmt.Errorf("%s is only used by auth_type pat", key)
+		}
 	}
-	settingValue, err := extractSettings(profileString, "server_hostname")
-	return settingValue, err
+	return nil
 }
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"testing"
+
+	"example.com/termquery/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseProfiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		want     []Profile
+	}{
+		{
+			name:     "empty file",
+			contents: "",
+			want:     []Profile{},
+		},
+		{
+			name:     "colon separated",
+			contents: "[databricks]\nserver_hostname:adb-1.azuredatabricks.net\nhttp_path:/sql/1.0/warehouses/abc\naccess_token:dapi123\n",
+			want:     []Profile{{Name: "databricks", ServerHostname: "adb-1.azuredatabricks.net", HttpPath: "/sql/1.0/warehouses/abc", AccessToken: "dapi123"}},
+		},
+		{
+			name:     "equals separated with whitespace",
+			contents: "  [ dev ]  \n  server_hostname =  adb-2.net  \n\thttp_path\t= /sql/x\n",
+			want:     []Profile{{Name: "dev", ServerHostname: "adb-2.net", HttpPath: "/sql/x"}},
+		},
+		{
+			name:     "prefix of another profile name",
+			contents: "[dev2]\nserver_hostname:two\n[dev]\nserver_hostname:one\n",
+			want:     []Profile{{Name: "dev2", ServerHostname: "two"}, {Name: "dev", ServerHostname: "one"}},
+		},
+		{
+			name:     "values containing separators and key names",
+			contents: "[p]\nhttp_path = https://host:443/sql\naccess_token: http_path=abc\n",
+			want:     []Profile{{Name: "p", HttpPath: "https://host:443/sql", AccessToken: "http_path=abc"}},
+		},
+		{
+			name:     "comments",
+			contents: "# team profiles\n; old style\n[p]\nserver_hostname = host # the eu workspace\naccess_token = tok ; rotated monthly\n",
+			want:     []Profile{{Name: "p", ServerHostname: "host", AccessToken: "tok"}},
+		},
+		{
+			name:     "quoted values",
+			contents: "[p]\naccess_token = \"a # b\"  # kept\nhttp_path = ' /padded '\nserver_hostname = \"say \\\"hi\\\"\"\n",
+			want:     []Profile{{Name: "p", ServerHostname: `say "hi"`, HttpPath: " /padded ", AccessToken: "a # b"}},
+		},
+		{
+			name:     "oauth",
+			contents: "[m2m]\nauth_type = oauth-m2m\nclient_id = abc\nclient_secret = s3cret\n[u2m]\nauth_type = oauth-u2m\n",
+			want:     []Profile{{Name: "m2m", AuthType: "oauth-m2m", ClientID: "abc", ClientSecret: "s3cret"}, {Name: "u2m", AuthType: "oauth-u2m"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			profiles, err := parseProfiles("profiles", []byte(test.contents))
+			assert.Nil(t, err)
+			assert.Equal(t, test.want, profiles)
+		})
+	}
+}
+
+func TestParseProfilesErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		message  string
+	}{
+		{"setting before a section", "server_hostname = host\n", "profiles:1: setting outside of a [section]"},
+		{"unclosed section", "[p\n", `profiles:1: expected [section], not "[p"`},
+		{"empty section name", "[ ]\n", `profiles:1: expected [section], not "[ ]"`},
+		{"duplicate section", "[p]\n\n[p]\n", "profiles:3: section [p] is already defined on line 1"},
+		{"duplicate setting", "[p]\nhttp_path = a\nhttp_path = b\n", "profiles:3: http_path is already set on line 2"},
+		{"no separator", "[p]\nhttp_path\n", `profiles:2: expected key = value, not "http_path"`},
+		{"unknown setting", "[p]\ntoken = x\n", `profiles:2: [p]: unknown setting "token"`},
+		{"unclosed quote", "[p]\naccess_token = \"abc\n", "profiles:2: access_token: missing closing quote"},
+		{"unknown auth type", "[p]\nauth_type = basic\n", `profiles:1: [p]: auth_type must be one of pat, oauth-m2m, oauth-u2m, not "basic"`},
+		{"m2m without a secret", "[p]\nauth_type = oauth-m2m\nclient_id = abc\n", "profiles:1: [p]: oauth-m2m needs a client_id and client_secret"},
+		{"token with oauth", "[p]\nauth_type = oauth-u2m\naccess_token = abc\n", "profiles:1: [p]: access_token is only used by auth_type pat"},
+		{"text after quote", "[p]\naccess_token = \"abc\" def\n", `profiles:2: access_token: unexpected "def" after the quoted value`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := parseProfiles("profiles", []byte(test.contents))
+			assert.EqualError(t, err, test.message)
+		})
+	}
+}
+
+func memoryProfileParams(files map[string][]byte, env map[string]string) ConfigParams {
+	return ConfigParams{
+		ConfigPath: "conf",
+		GetEnvFunc: func(name string) string { return env[name] },
+		ReadFileFunc: func(name string) ([]byte, error) {
+			contents, ok := files[name]
+			if !ok {
+				return nil, fs.ErrNotExist
+			}
+			return contents, nil
+		},
+		WriteFileFunc: func(name string, data []byte, perm os.FileMode) error {
+			files[name] = data
+			return nil
+		},
+	}
+}
+
+func TestAddAndRemoveProfile(t *testing.T) {
+	fileName := path.Join("conf", constants.ProfilesFileName)
+	files := map[string][]byte{fileName: []byte("# shared warehouse\n[dev]\nserver_hostname = dev.net\n\n# production, careful\n[prod]\nserver_hostname = prod.net\n")}
+	params := memoryProfileParams(files, nil)
+
+	err := AddProfile(params, Profile{Name: "eu", ServerHostname: "eu.net", HttpPath: "/sql/1.0/warehouses/1", AccessToken: "tok#en"})
+	assert.Nil(t, err)
+	profiles, err := LoadProfiles(params)
+	assert.Nil(t, err)
+	assert.Equal(t, Profile{Name: "eu", ServerHostname: "eu.net", HttpPath: "/sql/1.0/warehouses/1", AccessToken: "tok#en"}, profiles[2])
+	assert.EqualError(t, AddProfile(params, Profile{Name: "dev"}), "profile dev already exists")
+	assert.EqualError(t, AddProfile(params, Profile{Name: "a]b"}), `invalid profile name "a]b"`)
+
+	assert.Nil(t, RemoveProfile(params, "dev"))
+	assert.Equal(t, "\n# production, careful\n[prod]\nserver_hostname = prod.net\n\n[eu]\nserver_hostname = eu.net\nhttp_path = /sql/1.0/warehouses/1\naccess_token = \"tok#en\"\n", string(files[fileName]))
+	assert.Nil(t, RemoveProfile(params, "eu"))
+	assert.Equal(t, "\n# production, careful\n[prod]\nserver_hostname = prod.net\n\n", string(files[fileName]))
+	assert.EqualError(t, RemoveProfile(params, "eu"), "profile eu not in conf/profiles")
+}
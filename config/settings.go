@@ -0,0 +1,261 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"example.com/termquery/constants"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Source is where the effective value of a setting came from.
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "config file"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
+// Setting is the effective value of a setting. Origin narrows down the
+// source, such as the line of the config file or the name of the variable.
+type Setting struct {
+	Key    string
+	Value  string
+	Source Source
+	Origin string
+}
+
+// settingKeys are the settings with a single value, in the order of config.yaml.
+var settingKeys = []string{
+	"max_number_historical_queries",
+	"force_use_neovim",
+	"default_profile",
+	"schema_cache_ttl_minutes",
+	"format_on_save",
+	"theme",
+}
+
+// Keys lists every setting of c. Themes and key bindings are nested, so each
+// colour and action is a key of its own, like themes.mine.header.
+func (c Config) Keys() []string {
+	keys := slices.Clone(settingKeys)
+	for _, name := range sortedKeys(c.Themes) {
+		for _, colour := range sortedKeys(c.Themes[name]) {
+			keys = append(keys, "themes."+name+"."+colour)
+		}
+	}
+	for _, action := range sortedKeys(c.Keymap) {
+		keys = append(keys, "keymap."+action)
+	}
+	return keys
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// Get formats the value of a setting the way it is written in config.yaml.
+func (c Config) Get(key string) (string, error) {
+	switch key {
+	case "max_number_historical_queries":
+		return strconv.Itoa(c.MaxNumberHistoricalQueries), nil
+	case "force_use_neovim":
+		return strconv.FormatBool(c.ForceUseNeovim), nil
+	case "default_profile":
+		return c.DefaultProfile, nil
+	case "schema_cache_ttl_minutes":
+		return strconv.Itoa(c.SchemaCacheTTLMinutes), nil
+	case "format_on_save":
+		return strconv.FormatBool(c.FormatOnSave), nil
+	case "theme":
+		return c.Theme, nil
+	}
+	if name, ok := strings.CutPrefix(key, "themes."); ok {
+		theme, colour, _ := strings.Cut(name, ".")
+		if value, ok := c.Themes[theme][colour]; ok {
+			return value, nil
+		}
+	}
+	if action, ok := strings.CutPrefix(key, "keymap."); ok {
+		if keys, ok := c.Keymap[action]; ok {
+			out, err := yaml.Marshal(keySequence(keys))
+			return strings.TrimSpace(string(out)), err
+		}
+	}
+	return "", fmt.Errorf("unknown setting %q", key)
+}
+
+// keySequence is a list of keys as a flow sequence, [s, ctrl+s].
+func keySequence(keys []string) *yaml.Node {
+	sequence := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+	for _, k := range keys {
+		sequence.Content = append(sequence.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: k})
+	}
+	return sequence
+}
+
+// Settings lists the effective settings of config, which was loaded from the
+// config file, with where each value came from. The overrides given, like
+// flags, take precedence over the environment.
+func Settings(params ConfigParams, config Config, overrides []Setting) ([]Setting, error) {
+	overrides = append(EnvOverrides(params.GetEnvFunc), overrides...)
+	fileName := path.Join(params.ConfigPath, constants.ConfigFileName)
+	contents, err := params.ReadFileFunc(fileName)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(contents, &root); err != nil {
+		return nil, fmt.Errorf("%s: %w", fileName, err)
+	}
+
+	var settings []Setting
+	for _, key := range config.Keys() {
+		value, err := config.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		setting := Setting{Key: key, Value: value, Source: SourceDefault}
+		if node, _ := lookup(&root, key); node != nil {
+			setting.Source = SourceFile
+			setting.Origin = fmt.Sprintf("%s:%d", fileName, node.Line)
+		}
+		for _, o := range overrides {
+			if o.Key == key {
+				setting.Source, setting.Origin = o.Source, o.Origin
+			}
+		}
+		settings = append(settings, setting)
+	}
+	return settings, nil
+}
+
+// lookup finds the key node of a dotted key in a YAML document, along with
+// the mapping it is in. Missing mappings are reported as a nil key node.
+func lookup(root *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 {
+		return nil, nil
+	}
+	mapping := root.Content[0]
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		if mapping.Kind != yaml.MappingNode {
+			return nil, nil
+		}
+		j := mappingIndex(mapping, part)
+		if j < 0 {
+			return nil, nil
+		}
+		if i == len(parts)-1 {
+			return mapping.Content[j], mapping
+		}
+		mapping = mapping.Content[j+1]
+	}
+	return nil, nil
+}
+
+// mappingIndex is the index of key's node in the content of a mapping, or -1.
+func mappingIndex(mapping *yaml.Node, key string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// SetValue writes a setting into the contents of a config file. The file is
+// edited as a YAML tree, so its comments and the order of its settings are
+// kept. Key bindings take one or more keys, every other setting one value.
+// The result is not validated, which is left to Parse.
+func SetValue(contents []byte, key string, values []string) ([]byte, error) {
+	value, err := settingNode(key, values)
+	if err != nil {
+		return nil, err
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(contents, &root); err != nil {
+		return nil, err
+	}
+	if root.Kind == 0 {
+		root = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	mapping := root.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("the config file is not a mapping of settings")
+	}
+
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		j := mappingIndex(mapping, part)
+		if j < 0 {
+			child := &yaml.Node{Kind: yaml.MappingNode}
+			if i == len(parts)-1 {
+				child = value
+			}
+			mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: part}, child)
+			mapping = child
+			continue
+		}
+		existing := mapping.Content[j+1]
+		if i == len(parts)-1 {
+			// comments belong to the setting rather than to its old value
+			value.HeadComment, value.LineComment, value.FootComment = existing.HeadComment, existing.LineComment, existing.FootComment
+			mapping.Content[j+1] = value
+			break
+		}
+		if existing.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("%s is not a mapping", strings.Join(parts[:i+1], "."))
+		}
+		mapping = existing
+	}
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&root); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), encoder.Close()
+}
+
+// settingNode is the YAML value of a setting. Numbers and booleans are left
+// plain, so that decoding rejects a value of the wrong type.
+func settingNode(key string, values []string) (*yaml.Node, error) {
+	if action, ok := strings.CutPrefix(key, "keymap."); ok && action != "" && !strings.Contains(action, ".") {
+		if len(values) == 0 {
+			return nil, fmt.Errorf("%s: expected one or more keys", key)
+		}
+		return keySequence(values), nil
+	}
+	if len(values) != 1 {
+		return nil, fmt.Errorf("%s: expected one value, not %d", key, len(values))
+	}
+	switch key {
+	case "max_number_historical_queries", "schema_cache_ttl_minutes", "force_use_neovim", "format_on_save":
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: values[0]}, nil
+	case "default_profile", "theme":
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: values[0]}, nil
+	}
+	if name, ok := strings.CutPrefix(key, "themes."); ok {
+		if theme, colour, ok := strings.Cut(name, "."); ok && theme != "" && colour != "" && !strings.Contains(colour, ".") {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: values[0]}, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown setting %q", key)
+}
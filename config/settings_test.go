@@ -0,0 +1,80 @@
+package config
+
+import (
+	"io/fs"
+	"path"
+	"testing"
+
+	"example.com/termquery/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetValueKeepsComments(t *testing.T) {
+	contents := []byte("# termquery\n\n# how many queries\nmax_number_historical_queries: 10 # at most\ntheme: dark\n")
+
+	contents, err := SetValue(contents, "max_number_historical_queries", []string{"20"})
+	assert.Nil(t, err)
+	contents, err = SetValue(contents, "default_profile", []string{"123"})
+	assert.Nil(t, err)
+	contents, err = SetValue(contents, "keymap.sort", []string{"s", " "})
+	assert.Nil(t, err)
+	contents, err = SetValue(contents, "themes.mine.header", []string{"#ffffff"})
+	assert.Nil(t, err)
+
+	assert.Equal(t, `# termquery
+
+# how many queries
+max_number_historical_queries: 20 # at most
+theme: dark
+default_profile: "123"
+keymap:
+  sort: [s, ' ']
+themes:
+  mine:
+    header: '#ffffff'
+`, string(contents))
+	config, err := Parse("config.yaml", contents)
+	assert.Nil(t, err)
+	assert.Equal(t, "123", config.DefaultProfile)
+	assert.Equal(t, []string{"s", " "}, config.Keymap["sort"])
+}
+
+func TestSetValueErrors(t *testing.T) {
+	_, err := SetValue(nil, "max_queries", []string{"3"})
+	assert.EqualError(t, err, `unknown setting "max_queries"`)
+	_, err = SetValue(nil, "theme", []string{"dark", "light"})
+	assert.EqualError(t, err, "theme: expected one value, not 2")
+	_, err = SetValue(nil, "themes.mine", []string{"dark"})
+	assert.EqualError(t, err, `unknown setting "themes.mine"`)
+	_, err = SetValue([]byte("themes: none\n"), "themes.mine.header", []string{"red"})
+	assert.EqualError(t, err, "themes is not a mapping")
+}
+
+func TestSettingsSources(t *testing.T) {
+	contents := []byte("theme: light\nkeymap:\n  quit: [Q]\n")
+	params := ConfigParams{
+		ConfigPath: "conf",
+		GetEnvFunc: func(string) string { return "" },
+		ReadFileFunc: func(name string) ([]byte, error) {
+			if name != path.Join("conf", constants.ConfigFileName) {
+				return nil, fs.ErrNotExist
+			}
+			return contents, nil
+		},
+	}
+	config, err := Parse("config.yaml", contents)
+	assert.Nil(t, err)
+	config.DefaultProfile = "staging"
+	overrides := []Setting{{Key: "default_profile", Value: "staging", Source: SourceFlag, Origin: "--profile"}}
+
+	settings, err := Settings(params, config, overrides)
+	assert.Nil(t, err)
+	bySetting := map[string]Setting{}
+	for _, s := range settings {
+		bySetting[s.Key] = s
+	}
+	assert.Equal(t, Setting{"theme", "light", SourceFile, "conf/config.yaml:1"}, bySetting["theme"])
+	assert.Equal(t, Setting{"keymap.quit", "[Q]", SourceFile, "conf/config.yaml:3"}, bySetting["keymap.quit"])
+	assert.Equal(t, Setting{"default_profile", "staging", SourceFlag, "--profile"}, bySetting["default_profile"])
+	assert.Equal(t, Setting{Key: "format_on_save", Value: "false", Source: SourceDefault}, bySetting["format_on_save"])
+}
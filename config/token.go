@@ -0,0 +1,73 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"example.com/termquery/constants"
+)
+
+// accessToken reads the token of a profile that doesn't write it out.
+func accessToken(params ConfigParams, profile Profile) (string, error) {
+	var token string
+	switch {
+	case profile.AccessTokenCmd != "":
+		// stdin may be the JSON-RPC stream of the language server, so the
+		// command gets none; pinentry and the like ask on the terminal itself
+		var out bytes.Buffer
+		cmd := params.CommandFunc("sh", "-c", profile.AccessTokenCmd)
+		cmd.SetStdin(strings.NewReader(""))
+		cmd.SetStdout(&out)
+		if err := cmd.Run(); err != nil {
+			return "", fmt.Errorf("profile %s: access_token_cmd failed: %w", profile.Name, err)
+		}
+		// like pass, a command may print more than the token after the first line
+		token, _, _ = strings.Cut(out.String(), "\n")
+	case profile.AccessTokenFile != "":
+		contents, err := params.ReadFileFunc(tokenFilePath(params, profile.AccessTokenFile))
+		if err != nil {
+			return "", fmt.Errorf("profile %s: access_token_file: %w", profile.Name, err)
+		}
+		token = string(contents)
+	case profile.AccessTokenEnv != "":
+		token = params.GetEnvFunc(profile.AccessTokenEnv)
+		if token == "" {
+			return "", fmt.Errorf("profile %s: %s is not set", profile.Name, profile.AccessTokenEnv)
+		}
+	}
+	return strings.TrimSpace(token), nil
+}
+
+// tokenFilePath resolves ~ to the home directory and relative paths from the
+// config directory.
+func tokenFilePath(params ConfigParams, name string) string {
+	if rest, ok := strings.CutPrefix(name, "~/"); ok {
+		return filepath.Join(params.GetEnvFunc("HOME"), rest)
+	}
+	if !filepath.IsAbs(name) {
+		return filepath.Join(params.ConfigPath, name)
+	}
+	return name
+}
+
+// CheckProfilesPermissions reports a profiles file anyone can read, as it
+// may hold access tokens.
+func CheckProfilesPermissions(params ConfigParams) error {
+	// permissions on Windows don't follow the Unix bits
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	fileName := path.Join(params.ConfigPath, constants.ProfilesFileName)
+	info, err := params.StatFunc(fileName)
+	if err != nil {
+		return nil
+	}
+	if info.Mode().Perm()&0o004 != 0 {
+		return fmt.Errorf("%s is readable by every user (mode %04o), restrict it with chmod 600 %s", fileName, info.Mode().Perm(), fileName)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"example.com/termquery/cache"
+	"example.com/termquery/constants"
+	"github.com/stretchr/testify/assert"
+)
+
+type tokenCommand struct {
+	output string
+	err    error
+	stdin  io.Reader
+	stdout io.Writer
+}
+
+func (c *tokenCommand) Run() error {
+	io.WriteString(c.stdout, c.output)
+	return c.err
+}
+func (c *tokenCommand) SetStdin(r io.Reader)  { c.stdin = r }
+func (c *tokenCommand) SetStdout(w io.Writer) { c.stdout = w }
+func (c *tokenCommand) SetStderr(w io.Writer) {}
+
+func TestGetProfileReadsIndirectTokens(t *testing.T) {
+	files := map[string][]byte{
+		path.Join("conf", constants.ProfilesFileName): []byte(`[cmd]
+access_token_cmd = pass show databricks/dev
+[file]
+access_token_file = tokens/prod
+[env]
+access_token_env = PROD_TOKEN
+`),
+		filepath.Join("conf", "tokens", "prod"): []byte("from-file\n"),
+	}
+	env := map[string]string{"PROD_TOKEN": "from-env"}
+	params := memoryProfileParams(files, env)
+	var ran []string
+	params.CommandFunc = func(name string, args ...string) cache.Command {
+		ran = append(append(ran, name), args...)
+		return &tokenCommand{output: "from-cmd\nlogin: me\n"}
+	}
+
+	profile, err := GetProfile(params, "cmd")
+	assert.Nil(t, err)
+	assert.Equal(t, "from-cmd", profile.AccessToken)
+	assert.Equal(t, []string{"sh", "-c", "pass show databricks/dev"}, ran)
+
+	profile, err = GetProfile(params, "file")
+	assert.Nil(t, err)
+	assert.Equal(t, "from-file", profile.AccessToken)
+
+	profile, err = GetProfile(params, "env")
+	assert.Nil(t, err)
+	assert.Equal(t, "from-env", profile.AccessToken)
+
+	delete(env, "PROD_TOKEN")
+	_, err = GetProfile(params, "env")
+	assert.EqualError(t, err, "profile env: PROD_TOKEN is not set")
+
+	params.CommandFunc = func(name string, args ...string) cache.Command {
+		return &tokenCommand{err: errors.New("exit status 1")}
+	}
+	_, err = GetProfile(params, "cmd")
+	assert.EqualError(t, err, "profile cmd: access_token_cmd failed: exit status 1")
+}
+
+func TestAccessTokenCmdGetsNoStdin(t *testing.T) {
+	params := memoryProfileParams(map[string][]byte{}, map[string]string{})
+	cmd := &tokenCommand{output: "token\n"}
+	params.CommandFunc = func(name string, args ...string) cache.Command { return cmd }
+
+	token, err := accessToken(params, Profile{Name: "cmd", AccessTokenCmd: "pass show databricks/dev"})
+	assert.Nil(t, err)
+	assert.Equal(t, "token", token)
+	if assert.NotNil(t, cmd.stdin) {
+		input, _ := io.ReadAll(cmd.stdin)
+		assert.Empty(t, input)
+	}
+}
+
+func TestProfileTakesOneToken(t *testing.T) {
+	_, err := parseProfiles("profiles", []byte("[p]\naccess_token = a\naccess_token_env = B\n"))
+	assert.EqualError(t, err, "profiles:3: [p]: access_token and access_token_env can't both be set")
+}
+
+func TestCheckProfilesPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no Unix permissions")
+	}
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, constants.ProfilesFileName)
+	params := ConfigParams{ConfigPath: dir, StatFunc: os.Stat}
+
+	assert.Nil(t, os.WriteFile(fileName, nil, 0600))
+	assert.Nil(t, CheckProfilesPermissions(params))
+
+	assert.Nil(t, os.Chmod(fileName, 0644))
+	assert.ErrorContains(t, CheckProfilesPermissions(params), "is readable by every user (mode 0644)")
+}
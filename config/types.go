@@ -3,15 +3,20 @@ package config
 import (
 	"log/slog"
 
+	"example.com/termquery/cache"
 	"example.com/termquery/utils"
 )
 
 type ConfigParams struct {
-	Logger        *slog.Logger
-	ConfigPath    string
-	ReadDirFunc   utils.ReadDirFunc
-	MkdirFunc     utils.MkdirFunc
-	StatFunc      utils.StatFunc
-	WriteFileFunc utils.WriteFileFunc
-	ReadFileFunc  utils.ReadFileFunc
+	Logger     *slog.Logger
+	ConfigPath string
+	// DatabricksConfigPath is the profiles file of the Databricks CLI
+	DatabricksConfigPath string
+	ReadDirFunc          utils.ReadDirFunc
+	MkdirFunc            utils.MkdirFunc
+	StatFunc             utils.StatFunc
+	WriteFileFunc        utils.WriteFileFunc
+	ReadFileFunc         utils.ReadFileFunc
+	GetEnvFunc           utils.GetEnvFunc
+	CommandFunc          cache.CommandFunc
 }
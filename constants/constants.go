@@ -10,5 +10,15 @@ const DefaultApplicationConfigDirectory string = "termquery"
 
 const DefaultProfileName string = "databricks"
 
-const ConfigFileName string = "config"
+const ConfigFileName string = "config.yaml"
+const LegacyConfigFileName string = "config"
 const ProfilesFileName string = "profiles"
+
+const SchemaCacheDirectory string = "schema"
+const DefaultSchemaCacheTTLMinutes int = 24 * 60
+
+const MetadataCacheDirectory string = "metadata"
+
+const TokenCacheDirectory string = "tokens"
+
+const DefaultTheme string = "auto"
@@ -0,0 +1,317 @@
+// Package format pretty prints Databricks SQL: keywords are upper cased, each
+// clause starts a new line, SELECT lists get one item per line and subqueries
+// and CTE bodies are indented. Comments are preserved.
+package format
+
+import (
+	"strings"
+)
+
+// clauses start a new line at the indentation of the enclosing query. Longer
+// sequences are listed first so they win over their prefixes.
+var clauses = [][]string{
+	{"LEFT", "OUTER", "JOIN"}, {"RIGHT", "OUTER", "JOIN"}, {"FULL", "OUTER", "JOIN"},
+	{"LEFT", "SEMI", "JOIN"}, {"LEFT", "ANTI", "JOIN"}, {"LATERAL", "VIEW", "OUTER"},
+	{"GROUP", "BY"}, {"ORDER", "BY"}, {"SORT", "BY"}, {"CLUSTER", "BY"}, {"DISTRIBUTE", "BY"},
+	{"INNER", "JOIN"}, {"LEFT", "JOIN"}, {"RIGHT", "JOIN"}, {"FULL", "JOIN"}, {"CROSS", "JOIN"},
+	{"SEMI", "JOIN"}, {"ANTI", "JOIN"}, {"NATURAL", "JOIN"}, {"LATERAL", "VIEW"},
+	{"UNION", "ALL"}, {"UNION", "DISTINCT"}, {"INSERT", "INTO"}, {"INSERT", "OVERWRITE"},
+	{"DELETE", "FROM"}, {"MERGE", "INTO"},
+	{"SELECT"}, {"FROM"}, {"WHERE"}, {"HAVING"}, {"QUALIFY"}, {"LIMIT"}, {"OFFSET"},
+	{"WINDOW"}, {"UNION"}, {"INTERSECT"}, {"EXCEPT"}, {"MINUS"}, {"WITH"}, {"VALUES"},
+	{"JOIN"}, {"UPDATE"}, {"SET"},
+}
+
+// listClauses put each comma separated item on its own line.
+var listClauses = map[string]bool{"SELECT": true, "VALUES": true}
+
+// conditionClauses put each top level AND / OR on its own line.
+func isConditionClause(clause string) bool {
+	return clause == "WHERE" || clause == "HAVING" || clause == "QUALIFY" || strings.HasSuffix(clause, "JOIN")
+}
+
+type context struct {
+	subquery bool
+	base     int
+	clause   string
+	between  bool
+}
+
+type line struct {
+	indent int
+	text   strings.Builder
+}
+
+type formatter struct {
+	tokens         []token
+	lines          []*line
+	stack          []context
+	prev           *token
+	prevUnary      bool
+	pendingNewline bool
+}
+
+// Format returns sql reformatted. Formatting is idempotent.
+func Format(sql string) string {
+	f := formatter{
+		tokens: tokenize(sql),
+		lines:  []*line{{}},
+		stack:  []context{{subquery: true}},
+	}
+	f.run()
+	var out []string
+	for _, l := range f.lines {
+		text := strings.TrimRight(l.text.String(), " ")
+		if text == "" {
+			out = append(out, "")
+			continue
+		}
+		out = append(out, strings.Repeat("  ", l.indent)+text)
+	}
+	result := strings.Trim(strings.Join(out, "\n"), "\n")
+	if result == "" {
+		return ""
+	}
+	return result + "\n"
+}
+
+func (f *formatter) current() *context {
+	return &f.stack[len(f.stack)-1]
+}
+
+func (f *formatter) line() *line {
+	return f.lines[len(f.lines)-1]
+}
+
+// newline starts a new line at indent unless the current line is still empty,
+// in which case only its indentation changes.
+func (f *formatter) newline(indent int) {
+	f.pendingNewline = false
+	if f.line().text.Len() == 0 {
+		f.line().indent = indent
+		return
+	}
+	f.lines = append(f.lines, &line{indent: indent})
+}
+
+func (f *formatter) blankLine() {
+	f.newline(0)
+	f.lines = append(f.lines, &line{})
+}
+
+func (f *formatter) needsSpace(tok token) bool {
+	prev := f.prev
+	switch {
+	case f.line().text.Len() == 0 || prev == nil:
+		return false
+	case strings.Contains(",;)].", tok.text) && tok.kind == punctuationToken:
+		return false
+	case tok.text == "::" || tok.text == ":":
+		return false
+	case prev.text == "(" || prev.text == "[" || prev.text == "." || prev.text == "::" || prev.text == ":":
+		return false
+	case f.prevUnary:
+		return false
+	case tok.text == "(" && (prev.kind == wordToken || prev.kind == quotedToken):
+		return false
+	case tok.text == "(" && prev.kind == keywordToken:
+		return tok.spaceBefore
+	case tok.text == "[" && (prev.kind == wordToken || prev.kind == quotedToken || prev.text == ")" || prev.text == "]"):
+		return false
+	}
+	return true
+}
+
+func (f *formatter) isUnary(tok token) bool {
+	if tok.kind != operatorToken || (tok.text != "-" && tok.text != "+") {
+		return false
+	}
+	prev := f.prev
+	if prev == nil || prev.kind == operatorToken || prev.text == "(" || prev.text == "," || prev.text == "[" {
+		return true
+	}
+	// non-reserved words like DAY may be names, after which a sign is an operator
+	if prev.kind == keywordToken && keywords[strings.ToUpper(prev.text)] {
+		upper := strings.ToUpper(prev.text)
+		return upper != "NULL" && upper != "TRUE" && upper != "FALSE" && upper != "END"
+	}
+	return false
+}
+
+func (f *formatter) write(tok token) {
+	if f.pendingNewline {
+		f.newline(f.line().indent)
+	}
+	text := tok.text
+	if tok.kind == keywordToken {
+		text = strings.ToUpper(text)
+	}
+	if f.needsSpace(tok) {
+		f.line().text.WriteString(" ")
+	}
+	f.line().text.WriteString(text)
+	if tok.kind != lineCommentToken && tok.kind != blockCommentToken {
+		unary := f.isUnary(tok)
+		f.prev = &tok
+		f.prevUnary = unary
+	}
+}
+
+// next returns the index of the first non comment token after i.
+func (f *formatter) next(i int) int {
+	for j := i + 1; j < len(f.tokens); j++ {
+		if f.tokens[j].kind != lineCommentToken && f.tokens[j].kind != blockCommentToken {
+			return j
+		}
+	}
+	return -1
+}
+
+func (f *formatter) upperAt(i int) string {
+	if i < 0 || i >= len(f.tokens) || f.tokens[i].kind != keywordToken {
+		return ""
+	}
+	return strings.ToUpper(f.tokens[i].text)
+}
+
+func (f *formatter) startsSubquery(open int) bool {
+	next := f.upperAt(f.next(open))
+	return next == "SELECT" || next == "WITH" || next == "VALUES"
+}
+
+// matchClause returns the clause starting at i and how many tokens it spans.
+func (f *formatter) matchClause(i int) (string, int) {
+	if f.tokens[i].kind != keywordToken || !f.current().subquery {
+		return "", 0
+	}
+	for _, words := range clauses {
+		matched := true
+		for j, word := range words {
+			if f.upperAt(i+j) != word {
+				matched = false
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+		after := i + len(words)
+		// SELECT * EXCEPT (col) is a column list, not a set operation.
+		if words[0] == "EXCEPT" && after < len(f.tokens) && f.tokens[after].text == "(" && !f.startsSubquery(after) {
+			return "", 0
+		}
+		return strings.Join(words, " "), len(words)
+	}
+	return "", 0
+}
+
+func (f *formatter) comment(i int) {
+	tok := f.tokens[i]
+	tok.text = strings.TrimRight(tok.text, " \t")
+	if tok.newlineBefore && f.prev != nil {
+		indent := f.line().indent
+		if next := f.next(i); next >= 0 {
+			if clause, _ := f.matchClause(next); clause != "" {
+				indent = f.current().base
+			} else if f.tokens[next].text == ")" && f.current().subquery && len(f.stack) > 1 {
+				indent = f.current().base - 1
+			}
+		}
+		f.newline(indent)
+	} else if !tok.newlineBefore && f.line().text.Len() == 0 && len(f.lines) > 1 {
+		// A trailing comment stays on the line it followed, even when that
+		// line has already been ended, e.g. after a comma.
+		pending := f.line()
+		f.lines = f.lines[:len(f.lines)-1]
+		f.pendingNewline = false
+		f.write(tok)
+		f.lines = append(f.lines, pending)
+		return
+	}
+	f.write(tok)
+	if tok.kind == lineCommentToken || (i+1 < len(f.tokens) && f.tokens[i+1].newlineBefore) {
+		f.pendingNewline = true
+	}
+}
+
+func (f *formatter) run() {
+	for i := 0; i < len(f.tokens); i++ {
+		tok := f.tokens[i]
+		ctx := f.current()
+
+		if tok.kind == lineCommentToken || tok.kind == blockCommentToken {
+			f.comment(i)
+			continue
+		}
+
+		if clause, n := f.matchClause(i); n > 0 {
+			f.newline(ctx.base)
+			for j := 0; j < n; j++ {
+				f.write(f.tokens[i+j])
+			}
+			i += n - 1
+			ctx.clause = clause
+			ctx.between = false
+			if listClauses[clause] {
+				for next := f.upperAt(i + 1); next == "DISTINCT" || next == "ALL"; next = f.upperAt(i + 1) {
+					i++
+					f.write(f.tokens[i])
+				}
+				f.newline(ctx.base + 1)
+			}
+			continue
+		}
+
+		upper := strings.ToUpper(tok.text)
+		switch {
+		case tok.kind == keywordToken && upper == "BETWEEN":
+			ctx.between = true
+			f.write(tok)
+
+		case tok.kind == keywordToken && (upper == "AND" || upper == "OR") && ctx.subquery && isConditionClause(ctx.clause):
+			if upper == "AND" && ctx.between {
+				ctx.between = false
+			} else {
+				f.newline(ctx.base + 1)
+			}
+			f.write(tok)
+
+		case tok.text == "," && ctx.subquery:
+			f.write(tok)
+			if listClauses[ctx.clause] {
+				f.newline(ctx.base + 1)
+			} else if ctx.clause == "WITH" {
+				f.newline(ctx.base)
+			}
+
+		case tok.text == "(":
+			subquery := f.startsSubquery(i)
+			f.write(tok)
+			base := ctx.base
+			if subquery {
+				base = f.line().indent + 1
+			}
+			f.stack = append(f.stack, context{subquery: subquery, base: base})
+
+		case tok.text == ")":
+			if len(f.stack) > 1 {
+				closed := f.stack[len(f.stack)-1]
+				f.stack = f.stack[:len(f.stack)-1]
+				if closed.subquery {
+					f.newline(closed.base - 1)
+				}
+			}
+			f.write(tok)
+
+		case tok.text == ";":
+			f.write(tok)
+			f.stack = []context{{subquery: true}}
+			if f.next(i) >= 0 {
+				f.blankLine()
+			}
+
+		default:
+			f.write(tok)
+		}
+	}
+}
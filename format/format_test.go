@@ -0,0 +1,62 @@
+package format
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+func TestFormatGoldenFiles(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "*.sql"))
+	assert.Nil(t, err)
+	assert.NotEmpty(t, inputs)
+
+	for _, input := range inputs {
+		t.Run(filepath.Base(input), func(t *testing.T) {
+			source, err := os.ReadFile(input)
+			assert.Nil(t, err)
+			golden := strings.TrimSuffix(input, ".sql") + ".golden"
+
+			formatted := Format(string(source))
+			if *update {
+				assert.Nil(t, os.WriteFile(golden, []byte(formatted), 0644))
+			}
+			expected, err := os.ReadFile(golden)
+			assert.Nil(t, err)
+
+			assert.Equal(t, string(expected), formatted)
+			assert.Equal(t, formatted, Format(formatted), "formatting should be idempotent")
+		})
+	}
+}
+
+func TestFormatEmpty(t *testing.T) {
+	assert.Equal(t, "", Format("  \n"))
+}
+
+func TestFormatKeepsKeywordIdentifiersAfterDot(t *testing.T) {
+	assert.Equal(t, "SELECT\n  t.date,\n  t.order\nFROM t\n", Format("select t.date, t.order from t"))
+}
+
+func TestFormatKeepsNonReservedWordsUsedAsNames(t *testing.T) {
+	formatted := Format("select type, year, user, explode(items), day - 1 from t where type = 'x' and year > 2020 group by type, year, user, day")
+	expected := `SELECT
+  type,
+  year,
+  user,
+  explode(items),
+  day - 1
+FROM t
+WHERE type = 'x'
+  AND year > 2020
+GROUP BY type, year, user, day
+`
+	assert.Equal(t, expected, formatted)
+	assert.Equal(t, "SELECT\n  INTERVAL '1' DAY TO SECOND AS span\nFROM t\n", Format("select interval '1' day to second as span from t"))
+}
@@ -0,0 +1,8 @@
+-- daily totals
+select
+  day, -- the partition column
+  /* gross */ sum(amount) as total
+from orders
+/* only the good rows */
+where status = 'ok' -- skip failures
+group by day
@@ -0,0 +1,3 @@
+with recent as (select id, amount from orders where day >= date'2024-01-01'),
+totals as (select id, sum(amount) total from recent group by id having sum(amount) > 100)
+select * from totals
@@ -0,0 +1,6 @@
+select * except (secret), raw:payload.items[0].price::decimal(10,2) price, `weird col`,
+  regexp_extract(name, r'(\d+)', 1) num, struct(a, b).a, -x as neg, interval 1 day as one_day
+from ${catalog}.sales.orders tablesample (10 percent)
+lateral view outer explode(items) i as item
+where amount between -5 and 5 and name rlike '^A'
+qualify rank() over (order by amount desc rows between unbounded preceding and current row) <= 3
@@ -0,0 +1,5 @@
+SELECT o.id, c.name FROM orders o
+inner join customers c on o.customer_id = c.id and c.active = true
+left outer join regions r on c.region_id = r.id
+cross join calendar
+left anti join refunds f using (id)
@@ -0,0 +1 @@
+select distinct a, b as bee, coalesce(c, 0) c from t where a = 1 and b <> 'x' order by a, b desc limit 5
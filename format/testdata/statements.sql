@@ -0,0 +1,2 @@
+use catalog main; insert into t values (1, 'a'), (2, 'b');
+select a from t union all select b from u
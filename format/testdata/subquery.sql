@@ -0,0 +1,2 @@
+select id from (select id, row_number() over (partition by id order by ts) rn from events) e
+where rn = 1 and id in (select id from allowed where kind not in ('a', 'b')) or exists (select 1 from audit a where a.id = e.id)
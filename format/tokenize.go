@@ -0,0 +1,217 @@
+package format
+
+import (
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	wordToken tokenKind = iota
+	keywordToken
+	numberToken
+	stringToken
+	quotedToken
+	operatorToken
+	punctuationToken
+	lineCommentToken
+	blockCommentToken
+)
+
+type token struct {
+	kind          tokenKind
+	text          string
+	spaceBefore   bool // whitespace separated it from the previous token
+	newlineBefore bool // a line break separated it from the previous token
+}
+
+const operatorChars = "<>=!|&+-*/%^~:"
+
+func isWordRune(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// scanQuoted returns the index just past the closing quote of the literal
+// starting at start, honouring backslash escapes and doubled quotes.
+func scanQuoted(runes []rune, start int) int {
+	quote := runes[start]
+	i := start + 1
+	for i < len(runes) {
+		switch {
+		case runes[i] == '\\' && quote != '`' && i+1 < len(runes):
+			i += 2
+		case runes[i] == quote && i+1 < len(runes) && runes[i+1] == quote:
+			i += 2
+		case runes[i] == quote:
+			return i + 1
+		default:
+			i++
+		}
+	}
+	return len(runes)
+}
+
+func tokenize(sql string) []token {
+	runes := []rune(strings.ReplaceAll(sql, "\r\n", "\n"))
+	var tokens []token
+	space, newline := false, true
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+		kind := punctuationToken
+
+		switch {
+		case unicode.IsSpace(r):
+			space = true
+			newline = newline || r == '\n'
+			i++
+			continue
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			kind = lineCommentToken
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			kind = blockCommentToken
+			i += 2
+			for i+1 < len(runes) && !(runes[i] == '*' && runes[i+1] == '/') {
+				i++
+			}
+			i = min(i+2, len(runes))
+		case r == '\'' || r == '"':
+			kind = stringToken
+			i = scanQuoted(runes, i)
+		case r == '`':
+			kind = quotedToken
+			i = scanQuoted(runes, i)
+		case r == '$' && i+1 < len(runes) && runes[i+1] == '{':
+			kind = wordToken
+			for i < len(runes) && runes[i] != '}' {
+				i++
+			}
+			i = min(i+1, len(runes))
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1]) &&
+			(len(tokens) == 0 || tokens[len(tokens)-1].kind != wordToken)):
+			kind = numberToken
+			for i < len(runes) && (isWordRune(runes[i]) || runes[i] == '.' ||
+				((runes[i] == '-' || runes[i] == '+') && (runes[i-1] == 'e' || runes[i-1] == 'E'))) {
+				i++
+			}
+		case isWordRune(r):
+			kind = wordToken
+			for i < len(runes) && isWordRune(runes[i]) {
+				i++
+			}
+			// Prefixed literals such as r'\d+' and X'1F' stay one token.
+			if i-start == 1 && i < len(runes) && (runes[i] == '\'' || runes[i] == '"') &&
+				strings.ContainsRune("rRxXbB", r) {
+				kind = stringToken
+				i = scanQuoted(runes, i)
+			}
+		case strings.ContainsRune(operatorChars, r):
+			kind = operatorToken
+			for i < len(runes) && strings.ContainsRune(operatorChars, runes[i]) &&
+				!(runes[i] == '-' && i+1 < len(runes) && runes[i+1] == '-') {
+				i++
+			}
+		default:
+			i++
+		}
+
+		text := string(runes[start:i])
+		if kind == wordToken && keywords[strings.ToUpper(text)] {
+			kind = keywordToken
+		}
+		tokens = append(tokens, token{kind: kind, text: text, spaceBefore: space, newlineBefore: newline})
+		space, newline = false, false
+	}
+
+	// Keywords used as qualified names, e.g. t.date, are identifiers.
+	for i := range tokens {
+		if tokens[i].kind != keywordToken {
+			continue
+		}
+		if (i > 0 && tokens[i-1].text == ".") || (i+1 < len(tokens) && tokens[i+1].text == ".") {
+			tokens[i].kind = wordToken
+		}
+	}
+
+	// The other words of keywords are only keywords where a name can't be:
+	// starting a statement, after a literal as in INTERVAL 1 DAY or 10 PERCENT,
+	// and after a statement word as in USE CATALOG.
+	prev := -1
+	for i := range tokens {
+		if tokens[i].kind == lineCommentToken || tokens[i].kind == blockCommentToken {
+			continue
+		}
+		upper := strings.ToUpper(tokens[i].text)
+		_, known := keywords[upper]
+		if tokens[i].kind == wordToken && known && (i+1 >= len(tokens) || tokens[i+1].text != ".") {
+			switch {
+			case prev < 0 || tokens[prev].text == ";":
+				tokens[i].kind = keywordToken
+			case tokens[prev].kind == numberToken || tokens[prev].kind == stringToken:
+				tokens[i].kind = keywordToken
+			case tokens[prev].kind == keywordToken && statementWords[strings.ToUpper(tokens[prev].text)]:
+				tokens[i].kind = keywordToken
+			}
+		}
+		prev = i
+	}
+	return tokens
+}
+
+// keywords are the words of Databricks SQL, including its type names and the
+// units of intervals. The reserved words, and the clause words that rarely
+// name anything, are always keywords and map to true. The others map to false
+// and are only keywords where a name can't be, so a column called day or type
+// keeps its casing.
+var keywords = map[string]bool{}
+
+// statementWords are followed by what kind of object a statement is about,
+// e.g. USE CATALOG or SHOW TABLES, and TO by the end of an interval.
+var statementWords = map[string]bool{
+	"ADD": true, "ALTER": true, "ANALYZE": true, "CREATE": true, "DESCRIBE": true, "DROP": true,
+	"EXPLAIN": true, "REFRESH": true, "SHOW": true, "TO": true, "TRUNCATE": true, "USE": true,
+}
+
+func init() {
+	for _, word := range strings.Fields(`
+		ADD ALL ALTER AND ANTI ANY AS ASC AUTHORIZATION BETWEEN BOTH BY CASE CAST CHECK CLUSTER
+		COLLATE COLUMN COMMIT CONSTRAINT CREATE CROSS CUBE CURRENT CURRENT_DATE CURRENT_TIME
+		CURRENT_TIMESTAMP CURRENT_USER DATE DEFAULT DELETE DESC DESCRIBE DISTINCT DISTRIBUTE
+		DROP ELSE END ESCAPE EXCEPT EXISTS EXPLAIN EXTERNAL FALSE FETCH FILTER FIRST FOLLOWING
+		FOR FOREIGN FROM FULL FUNCTION GRANT GROUP GROUPING HAVING IF ILIKE IN INNER INSERT
+		INTERSECT INTERVAL INTO IS JOIN LAST LATERAL LEADING LEFT LIKE LIMIT MATCHED MERGE MINUS
+		NATURAL NO NOT NULL NULLS OF OFFSET ON ONLY OR ORDER OUTER OVER OVERLAPS OVERWRITE
+		PARTITION PIVOT PRECEDING PRIMARY QUALIFY RANGE RECURSIVE REFERENCES REPLACE REVOKE
+		RIGHT RLIKE ROLLBACK ROLLUP ROW ROWS SELECT SEMI SESSION_USER SET SHOW SOME SORT TABLE
+		TABLESAMPLE TEMP TEMPORARY THEN TIMESTAMP TO TRAILING TRUE TRUNCATE TRY_CAST UNBOUNDED
+		UNION UNIQUE UNKNOWN UNPIVOT UPDATE USING VALUES VIEW WHEN WHERE WINDOW WITH`) {
+		keywords[word] = true
+	}
+	for _, word := range strings.Fields(`
+		AFTER ALWAYS ANALYZE ARCHIVE ARRAY AT BIGINT BINARY BOOLEAN BUCKET BUCKETS BYTE CACHE
+		CASCADE CATALOG CATALOGS CHANGE CHAR CHARACTER CLEAR CLUSTERED CODEGEN COLLECTION
+		COLUMNS COMMENT COMPACT COMPACTIONS COMPUTE CONCATENATE COST DATA DATABASE DATABASES
+		DATEADD DATEDIFF DAY DAYOFYEAR DAYS DBPROPERTIES DEC DECIMAL DEFINED DELIMITED DFS
+		DIRECTORIES DIRECTORY DIV DOUBLE ESCAPED EXCHANGE EXCLUDE EXPORT EXTENDED EXTRACT FIELDS
+		FILEFORMAT FLOAT FORMAT FORMATTED FUNCTIONS GENERATED GLOBAL HOUR HOURS IDENTIFIER
+		IGNORE IMPORT INCLUDE INDEX INDEXES INPATH INPUTFORMAT INT INTEGER ITEMS KEYS LAZY LINES
+		LIST LOAD LOCAL LOCATION LOCK LOCKS LOGICAL LONG MACRO MAP MICROSECOND MICROSECONDS
+		MILLISECOND MILLISECONDS MINUTE MINUTES MONTH MONTHS MSCK NAMESPACE NAMESPACES
+		NANOSECOND NANOSECONDS NUMERIC OPTION OPTIONS OUT OUTPUTFORMAT OVERLAY PARTITIONED
+		PARTITIONS PERCENT PERCENTILE_CONT PERCENTILE_DISC PLACING POSITION PRINCIPALS
+		PROPERTIES PURGE QUARTER QUERY REAL RECORDREADER RECORDWRITER RECOVER REDUCE REFRESH
+		REGEXP RENAME REPAIR REPEATABLE RESET RESPECT RESTRICT ROLE ROLES SCHEMA SCHEMAS SECOND
+		SECONDS SEPARATED SERDE SERDEPROPERTIES SETS SHORT SKEWED SMALLINT SORTED SOURCE START
+		STATISTICS STORED STRATIFY STRING STRUCT SUBSTR SUBSTRING SYNC SYSTEM_TIME
+		SYSTEM_VERSION TABLES TARGET TBLPROPERTIES TERMINATED TIME TIMESTAMPADD TIMESTAMPDIFF
+		TIMESTAMP_LTZ TIMESTAMP_NTZ TINYINT TOUCH TRANSACTION TRANSACTIONS TRANSFORM TRIM TYPE
+		UNARCHIVE UNCACHE UNLOCK UNSET USE USER VAR VARCHAR VARIABLE VERSION VIEWS VOID WEEK
+		WEEKS WITHIN YEAR YEARS ZONE`) {
+		keywords[word] = false
+	}
+}
@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"io"
 	"log/slog"
 	"os"
 	"sync"
@@ -23,6 +24,7 @@ var (
 type LoggerConfig struct {
 	Level  slog.Level
 	Format LogFormat
+	Output io.Writer // defaults to os.Stdout
 }
 
 // Init initializes the logger with config.
@@ -30,12 +32,16 @@ type LoggerConfig struct {
 func Init(config LoggerConfig) {
 	once.Do(func() {
 		var handler slog.Handler
+		output := config.Output
+		if output == nil {
+			output = os.Stdout
+		}
 
 		switch config.Format {
 		case FormatJSON:
-			handler = slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: config.Level})
+			handler = slog.NewJSONHandler(output, &slog.HandlerOptions{Level: config.Level})
 		case FormatText:
-			handler = slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: config.Level})
+			handler = slog.NewTextHandler(output, &slog.HandlerOptions{Level: config.Level})
 		default:
 			panic("unsupported log format")
 		}
@@ -0,0 +1,214 @@
+package lsp
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"example.com/termquery/schema"
+)
+
+var (
+	tableReference = regexp.MustCompile("(?i)\\b(?:from|join)\\s+([\\w.`]+)(?:\\s+(?:as\\s+)?(\\w+))?")
+	errorLocation  = regexp.MustCompile(`line (\d+), pos (\d+)`)
+)
+
+// aliasKeywords may follow a table name without being an alias for it.
+var aliasKeywords = map[string]bool{
+	"where": true, "join": true, "left": true, "right": true, "inner": true, "full": true,
+	"cross": true, "outer": true, "on": true, "group": true, "order": true, "limit": true,
+	"union": true, "having": true, "using": true, "natural": true, "lateral": true, "window": true,
+}
+
+func isIdentifierChar(c byte) bool {
+	return c == '_' || c == '.' || c == '`' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// offset converts an LSP position into a byte offset within text.
+func offset(text string, position Position) int {
+	lines := strings.SplitAfter(text, "\n")
+	total := 0
+	for i := 0; i < position.Line && i < len(lines); i++ {
+		total += len(lines[i])
+	}
+	if position.Line < len(lines) {
+		total += min(position.Character, len(strings.TrimRight(lines[position.Line], "\n")))
+	}
+	return min(total, len(text))
+}
+
+// wordAt returns the dotted identifier surrounding position and the part of it
+// that precedes position.
+func wordAt(text string, position Position) (string, string) {
+	at := offset(text, position)
+	start := at
+	for start > 0 && isIdentifierChar(text[start-1]) {
+		start--
+	}
+	end := at
+	for end < len(text) && isIdentifierChar(text[end]) {
+		end++
+	}
+	return text[start:end], text[start:at]
+}
+
+// referencedTables maps each table and alias named in FROM or JOIN clauses to its table.
+func referencedTables(snapshot schema.Snapshot, text string) map[string]schema.Table {
+	tables := map[string]schema.Table{}
+	for _, match := range tableReference.FindAllStringSubmatch(text, -1) {
+		table, ok := snapshot.FindTable(match[1])
+		if !ok {
+			continue
+		}
+		tables[strings.ToLower(table.Name)] = table
+		if alias := strings.ToLower(match[2]); alias != "" && !aliasKeywords[alias] {
+			tables[alias] = table
+		}
+	}
+	return tables
+}
+
+func columnDetail(column schema.Column) string {
+	detail := column.Type
+	if !column.Nullable {
+		detail += " not null"
+	}
+	return detail
+}
+
+// Complete offers catalogs, schemas, tables or columns depending on what has
+// been typed before position.
+func Complete(snapshot schema.Snapshot, text string, position Position) []CompletionItem {
+	_, prefix := wordAt(text, position)
+	items := []CompletionItem{}
+	qualifier := ""
+	if i := strings.LastIndex(prefix, "."); i >= 0 {
+		qualifier = strings.ToLower(strings.ReplaceAll(prefix[:i], "`", ""))
+	}
+
+	if qualifier == "" {
+		source := schema.SnapshotSource{Snapshot: snapshot}
+		catalogs, _ := source.Catalogs()
+		for _, c := range catalogs {
+			items = append(items, CompletionItem{Label: c, Kind: completionKindModule, Detail: "catalog"})
+		}
+		for _, t := range snapshot.Tables {
+			items = append(items, CompletionItem{Label: t.Name, Kind: completionKindClass, Detail: t.QualifiedName()})
+		}
+		for _, t := range referencedTables(snapshot, text) {
+			for _, c := range t.Columns {
+				items = append(items, CompletionItem{Label: c.Name, Kind: completionKindField, Detail: columnDetail(c)})
+			}
+		}
+		return dedupe(items)
+	}
+
+	if t, ok := referencedTables(snapshot, text)[qualifier]; ok {
+		for _, c := range t.Columns {
+			items = append(items, CompletionItem{Label: c.Name, Kind: completionKindField, Detail: columnDetail(c)})
+		}
+		return items
+	}
+
+	for _, t := range snapshot.Tables {
+		catalog, schemaName := strings.ToLower(t.Catalog), strings.ToLower(t.Schema)
+		switch qualifier {
+		case catalog:
+			items = append(items, CompletionItem{Label: t.Schema, Kind: completionKindModule, Detail: "schema"})
+		case catalog + "." + schemaName, schemaName:
+			items = append(items, CompletionItem{Label: t.Name, Kind: completionKindClass, Detail: t.QualifiedName()})
+		case catalog + "." + schemaName + "." + strings.ToLower(t.Name), schemaName + "." + strings.ToLower(t.Name):
+			for _, c := range t.Columns {
+				items = append(items, CompletionItem{Label: c.Name, Kind: completionKindField, Detail: columnDetail(c)})
+			}
+		}
+	}
+	return dedupe(items)
+}
+
+func dedupe(items []CompletionItem) []CompletionItem {
+	seen := map[string]bool{}
+	unique := items[:0]
+	for _, item := range items {
+		key := fmt.Sprintf("%d:%s", item.Kind, item.Label)
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, item)
+		}
+	}
+	return unique
+}
+
+// HoverAt describes the table or column under position.
+func HoverAt(snapshot schema.Snapshot, text string, position Position) (Hover, bool) {
+	word, _ := wordAt(text, position)
+	if word == "" {
+		return Hover{}, false
+	}
+
+	if t, ok := snapshot.FindTable(word); ok {
+		var b strings.Builder
+		fmt.Fprintf(&b, "**%s**\n\n", t.QualifiedName())
+		for _, c := range t.Columns {
+			fmt.Fprintf(&b, "- `%s` %s\n", c.Name, columnDetail(c))
+		}
+		return Hover{Contents: markupContent{Kind: "markdown", Value: b.String()}}, true
+	}
+
+	parts := strings.Split(strings.ToLower(strings.ReplaceAll(word, "`", "")), ".")
+	name := parts[len(parts)-1]
+	tables := referencedTables(snapshot, text)
+	if len(parts) > 1 {
+		t, ok := tables[parts[len(parts)-2]]
+		if !ok {
+			return Hover{}, false
+		}
+		tables = map[string]schema.Table{t.Name: t}
+	}
+	for _, t := range tables {
+		for _, c := range t.Columns {
+			if strings.ToLower(c.Name) != name {
+				continue
+			}
+			value := fmt.Sprintf("`%s` %s\n\n%s", c.Name, columnDetail(c), t.QualifiedName())
+			if c.Comment != "" {
+				value += "\n\n" + c.Comment
+			}
+			return Hover{Contents: markupContent{Kind: "markdown", Value: value}}, true
+		}
+	}
+	return Hover{}, false
+}
+
+// Diagnose dry runs text with explain and converts any error into a diagnostic,
+// using the line and position reported by the parser when present.
+func Diagnose(explain ExplainFunc, text string) []Diagnostic {
+	diagnostics := []Diagnostic{}
+	if strings.TrimSpace(text) == "" {
+		return diagnostics
+	}
+	err := explain(text)
+	if err == nil {
+		return diagnostics
+	}
+
+	position := Position{}
+	if match := errorLocation.FindStringSubmatch(err.Error()); match != nil {
+		line, _ := strconv.Atoi(match[1])
+		character, _ := strconv.Atoi(match[2])
+		position = Position{Line: max(line-1, 0), Character: character}
+	}
+	end := position
+	lines := strings.Split(text, "\n")
+	if position.Line < len(lines) {
+		end.Character = max(len(lines[position.Line]), position.Character)
+	}
+	return append(diagnostics, Diagnostic{
+		Range:    Range{Start: position, End: end},
+		Severity: severityError,
+		Source:   "termquery",
+		Message:  err.Error(),
+	})
+}
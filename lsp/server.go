@@ -0,0 +1,185 @@
+// Package lsp implements a small Language Server Protocol server over stdio so
+// editors can offer table and column completion, hover and EXPLAIN based
+// diagnostics while editing cached query files.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"sync"
+
+	"example.com/termquery/schema"
+)
+
+// ExplainFunc dry runs query, returning the error the warehouse reports for it.
+type ExplainFunc func(query string) error
+
+type Server struct {
+	Snapshot schema.Snapshot
+	Explain  ExplainFunc
+	Logger   *slog.Logger
+
+	documents map[string]string
+	writer    io.Writer
+	writeLock sync.Mutex
+	pending   sync.WaitGroup
+
+	// runs counts the diagnose runs of each document, so that a run
+	// finishing after a newer one doesn't publish outdated diagnostics
+	runs     map[string]int
+	runsLock sync.Mutex
+}
+
+// Serve handles messages from in until the client sends exit or in is closed.
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	s.documents = map[string]string{}
+	s.runs = map[string]int{}
+	s.writer = out
+	reader := bufio.NewReader(in)
+	defer s.pending.Wait()
+
+	for {
+		body, err := readMessage(reader)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			s.Logger.Error("Could not decode message", "error", err)
+			continue
+		}
+		if req.Method == "exit" {
+			return nil
+		}
+		s.handle(req)
+	}
+}
+
+func (s *Server) send(message any) {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+	if err := writeMessage(s.writer, message); err != nil {
+		s.Logger.Error("Could not write message", "error", err)
+	}
+}
+
+func (s *Server) reply(req request, result any) {
+	s.send(response{JSONRPC: "2.0", ID: req.ID, Result: result})
+}
+
+func (s *Server) replyError(req request, code int, message string) {
+	s.send(response{JSONRPC: "2.0", ID: req.ID, Error: &responseError{Code: code, Message: message}})
+}
+
+func (s *Server) handle(req request) {
+	switch req.Method {
+	case "initialize":
+		s.reply(req, map[string]any{
+			"capabilities": map[string]any{
+				"textDocumentSync": map[string]any{
+					"openClose": true,
+					"change":    1,
+					"save":      map[string]any{"includeText": true},
+				},
+				"completionProvider": map[string]any{"triggerCharacters": []string{"."}},
+				"hoverProvider":      true,
+			},
+			"serverInfo": map[string]any{"name": "termquery"},
+		})
+
+	case "shutdown":
+		s.reply(req, nil)
+
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if json.Unmarshal(req.Params, &params) == nil {
+			s.documents[params.TextDocument.URI] = params.TextDocument.Text
+			s.diagnose(params.TextDocument.URI)
+		}
+
+	case "textDocument/didChange":
+		var params didChangeParams
+		if json.Unmarshal(req.Params, &params) == nil && len(params.ContentChanges) > 0 {
+			s.documents[params.TextDocument.URI] = params.ContentChanges[len(params.ContentChanges)-1].Text
+		}
+
+	case "textDocument/didSave":
+		var params didSaveParams
+		if json.Unmarshal(req.Params, &params) == nil {
+			if params.Text != nil {
+				s.documents[params.TextDocument.URI] = *params.Text
+			}
+			s.diagnose(params.TextDocument.URI)
+		}
+
+	case "textDocument/didClose":
+		var params didOpenParams
+		if json.Unmarshal(req.Params, &params) == nil {
+			delete(s.documents, params.TextDocument.URI)
+			// outdates the runs still going, even if the document is reopened
+			s.runsLock.Lock()
+			s.runs[params.TextDocument.URI]++
+			s.runsLock.Unlock()
+		}
+
+	case "textDocument/completion":
+		var params textDocumentPositionParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			s.replyError(req, invalidParams, err.Error())
+			return
+		}
+		s.reply(req, Complete(s.Snapshot, s.documents[params.TextDocument.URI], params.Position))
+
+	case "textDocument/hover":
+		var params textDocumentPositionParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			s.replyError(req, invalidParams, err.Error())
+			return
+		}
+		hover, ok := HoverAt(s.Snapshot, s.documents[params.TextDocument.URI], params.Position)
+		if !ok {
+			s.reply(req, nil)
+			return
+		}
+		s.reply(req, hover)
+
+	default:
+		// Requests must always be answered, notifications never are.
+		if req.ID != nil {
+			s.replyError(req, methodNotFound, "method not supported: "+req.Method)
+		}
+	}
+}
+
+// diagnose dry runs the document in the background and publishes the outcome,
+// unless the document was diagnosed again or closed in the meantime.
+func (s *Server) diagnose(uri string) {
+	text := s.documents[uri]
+	if s.Explain == nil {
+		return
+	}
+	s.runsLock.Lock()
+	s.runs[uri]++
+	run := s.runs[uri]
+	s.runsLock.Unlock()
+	s.pending.Add(1)
+	go func() {
+		defer s.pending.Done()
+		diagnostics := Diagnose(s.Explain, text)
+		s.runsLock.Lock()
+		defer s.runsLock.Unlock()
+		if s.runs[uri] != run {
+			return
+		}
+		s.send(notification{
+			JSONRPC: "2.0",
+			Method:  "textDocument/publishDiagnostics",
+			Params:  publishDiagnosticsParams{URI: uri, Diagnostics: diagnostics},
+		})
+	}()
+}
@@ -0,0 +1,152 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"example.com/termquery/schema"
+)
+
+var testSnapshot = schema.Snapshot{
+	Profile:   "dev",
+	FetchedAt: time.Now(),
+	Tables: []schema.Table{
+		{Catalog: "main", Schema: "sales", Name: "orders", Columns: []schema.Column{
+			{Name: "id", Type: "bigint"},
+			{Name: "amount", Type: "double", Nullable: true, Comment: "gross value"},
+		}},
+		{Catalog: "main", Schema: "sales", Name: "customers", Columns: []schema.Column{
+			{Name: "id", Type: "bigint"},
+			{Name: "country", Type: "string", Nullable: true},
+		}},
+	},
+}
+
+func labels(items []CompletionItem) []string {
+	out := []string{}
+	for _, item := range items {
+		out = append(out, item.Label)
+	}
+	return out
+}
+
+func TestReadWriteMessageRoundTrip(t *testing.T) {
+	var buffer bytes.Buffer
+	err := writeMessage(&buffer, map[string]string{"method": "initialized"})
+	assert.Nil(t, err)
+
+	body, err := readMessage(bufio.NewReader(&buffer))
+
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"method":"initialized"}`, string(body))
+}
+
+func TestCompleteSchemaLevels(t *testing.T) {
+	text := "select * from main.sales."
+	items := Complete(testSnapshot, text, Position{Line: 0, Character: len(text)})
+	assert.Equal(t, []string{"orders", "customers"}, labels(items))
+
+	text = "select * from main."
+	items = Complete(testSnapshot, text, Position{Line: 0, Character: len(text)})
+	assert.Equal(t, []string{"sales"}, labels(items))
+}
+
+func TestCompleteAliasColumns(t *testing.T) {
+	text := "select o.\nfrom main.sales.orders o"
+	items := Complete(testSnapshot, text, Position{Line: 0, Character: 9})
+	assert.Equal(t, []string{"id", "amount"}, labels(items))
+}
+
+func TestCompleteUnqualifiedIncludesReferencedColumns(t *testing.T) {
+	text := "select  from sales.customers"
+	items := Complete(testSnapshot, text, Position{Line: 0, Character: 7})
+	assert.Contains(t, labels(items), "country")
+	assert.Contains(t, labels(items), "orders")
+	assert.NotContains(t, labels(items), "amount")
+}
+
+func TestHoverColumn(t *testing.T) {
+	text := "select amount from orders"
+	hover, ok := HoverAt(testSnapshot, text, Position{Line: 0, Character: 9})
+	assert.True(t, ok)
+	assert.Contains(t, hover.Contents.Value, "double")
+	assert.Contains(t, hover.Contents.Value, "gross value")
+}
+
+func TestDiagnoseUsesErrorLocation(t *testing.T) {
+	explain := func(query string) error {
+		return fmt.Errorf("[PARSE_SYNTAX_ERROR] Syntax error at or near 'form'.(line 2, pos 2)")
+	}
+	diagnostics := Diagnose(explain, "select *\n  form orders")
+
+	assert.Len(t, diagnostics, 1)
+	assert.Equal(t, Position{Line: 1, Character: 2}, diagnostics[0].Range.Start)
+	assert.Equal(t, Position{Line: 1, Character: 13}, diagnostics[0].Range.End)
+}
+
+func frame(body string) string {
+	return fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+func TestServeAnswersRequests(t *testing.T) {
+	in := strings.NewReader(
+		frame(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`) +
+			frame(`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"file:///q.sql","text":"select * from main."}}}`) +
+			frame(`{"jsonrpc":"2.0","id":2,"method":"textDocument/completion","params":{"textDocument":{"uri":"file:///q.sql"},"position":{"line":0,"character":19}}}`) +
+			frame(`{"jsonrpc":"2.0","id":3,"method":"unknown/method"}`) +
+			frame(`{"jsonrpc":"2.0","method":"exit"}`),
+	)
+	var out bytes.Buffer
+	server := Server{Snapshot: testSnapshot, Logger: slog.Default()}
+
+	err := server.Serve(in, &out)
+
+	assert.Nil(t, err)
+	assert.Contains(t, out.String(), `"hoverProvider":true`)
+	assert.Contains(t, out.String(), `"label":"sales"`)
+	assert.Contains(t, out.String(), `"code":-32601`)
+}
+
+func TestResponseHasResultOrError(t *testing.T) {
+	body, err := json.Marshal(response{JSONRPC: "2.0", ID: json.RawMessage("1")})
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"result":null}`, string(body))
+
+	body, err = json.Marshal(response{JSONRPC: "2.0", ID: json.RawMessage("2"), Error: &responseError{Code: methodNotFound, Message: "nope"}})
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":2,"error":{"code":-32601,"message":"nope"}}`, string(body))
+}
+
+func TestServeDropsOutdatedDiagnostics(t *testing.T) {
+	in := strings.NewReader(
+		frame(`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":"file:///q.sql","text":"select * form orders"}}}`) +
+			frame(`{"jsonrpc":"2.0","method":"textDocument/didSave","params":{"textDocument":{"uri":"file:///q.sql"},"text":"select * from orders"}}`) +
+			frame(`{"jsonrpc":"2.0","method":"exit"}`),
+	)
+	fixed := make(chan struct{})
+	explain := func(query string) error {
+		if strings.Contains(query, "form") {
+			// the first version is only done diagnosing after the second
+			<-fixed
+			return fmt.Errorf("Syntax error at or near 'form'")
+		}
+		close(fixed)
+		return nil
+	}
+	var out bytes.Buffer
+	server := Server{Snapshot: testSnapshot, Explain: explain, Logger: slog.Default()}
+
+	err := server.Serve(in, &out)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, strings.Count(out.String(), "publishDiagnostics"))
+	assert.NotContains(t, out.String(), "Syntax error")
+}
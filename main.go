@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"io"
 	"log/slog"
@@ -12,11 +13,14 @@ import (
 	"example.com/termquery/cache"
 	"example.com/termquery/config"
 	"example.com/termquery/logger"
+	"example.com/termquery/schema"
 	"example.com/termquery/sql"
+	"example.com/termquery/theme"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 )
 
 type RealCommand struct {
@@ -56,10 +60,12 @@ type model struct {
 	err             error
 }
 
+var spinnerStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Dark.Accent))
+
 func initialModel(channel chan bool) model {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
-	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+	s.Style = spinnerStyle
 	return model{
 		spinner:        s,
 		spinnerChannel: channel}
@@ -118,15 +124,17 @@ func RunQueryFromFileWithChannel(
 	logger *slog.Logger,
 	rowChannel chan []map[string]string,
 	colChannel chan []string,
+	typeChannel chan map[string]string,
 	errorChannel chan error,
 	spinnerChannel chan bool,
 ) {
 	defer wg.Done()
 
-	rows, columns, err := connection.RunQueryFromFile(filepath.Join(cacheParams.CachePath, fileName))
+	rows, columns, types, err := connection.RunQueryFromFile(filepath.Join(cacheParams.CachePath, fileName))
 	spinnerChannel <- true
 	rowChannel <- rows
 	colChannel <- columns
+	typeChannel <- types
 	errorChannel <- err
 }
 
@@ -134,16 +142,99 @@ type TestQuery struct {
 	col float32
 }
 
-func main() {
-
+// runQueryAndDisplay runs the cached query in fileName behind a spinner and
+// then opens the result viewer.
+func runQueryAndDisplay(cacheParams cache.CacheParams, fileName string, connection sql.Connection, keys sql.KeyMap, logger *slog.Logger) {
 	spinnerFinished := make(chan bool, 1)
 	rowChan := make(chan []map[string]string, 1)
 	colChan := make(chan []string, 1)
+	typeChan := make(chan map[string]string, 1)
 	errorChan := make(chan error, 1)
 
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go RunQueryFromFileWithChannel(cacheParams, fileName, connection, &wg, logger, rowChan, colChan, typeChan, errorChan, spinnerFinished)
+	model := initialModel(spinnerFinished)
+	p := tea.NewProgram(model, tea.WithAltScreen())
+	if _, err := p.Run(); err != nil {
+		fmt.Println(err)
+	}
+
+	wg.Wait()
+	rows := <-rowChan
+	columns := <-colChan
+	types := <-typeChan
+	err := <-errorChan
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+
+	query, err := os.ReadFile(filepath.Join(cacheParams.CachePath, fileName))
+	if err != nil {
+		logger.Error("Could not read query for explain", "error", err)
+	}
+
+	metadata, err := cache.ReadMetadata(cacheParams, fileName)
+	if err != nil {
+		logger.Error("Could not read query metadata", "error", err)
+	}
+	queryContext := sql.QueryContext{
+		Connection: connection,
+		Query:      string(query),
+		Layout:     sql.ColumnLayout{Order: metadata.ColumnOrder, Pinned: metadata.PinnedColumns},
+		SaveLayout: func(layout sql.ColumnLayout) {
+			metadata.ColumnOrder = layout.Order
+			metadata.PinnedColumns = layout.Pinned
+			err := cache.WriteMetadata(cacheParams, fileName, metadata)
+			if err != nil {
+				logger.Error("Could not save column layout", "error", err)
+			}
+		},
+	}
+
+	// sql.PrintRowsAsTableBasic(os.Stdout, rows)
+	sql.PrintRowsAsTableTea(rows, columns, types, queryContext, keys)
+}
+
+// applyTheme styles every view with the configured theme, in the colours the
+// terminal can show.
+func applyTheme(cfg config.Config) error {
+	t, err := theme.Resolve(cfg.Theme, cfg.Themes, lipgloss.HasDarkBackground)
+	if err != nil {
+		return err
+	}
+	lipgloss.SetColorProfile(theme.Profile(os.Getenv, termenv.NewOutput(os.Stdout).ColorProfile()))
+	sql.SetTheme(t)
+	schema.SetTheme(t)
+	spinnerStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(t.Accent))
+	formLabelStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(t.Accent)).Bold(true)
+	formErrorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(t.Error))
+	formHelpStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(t.Muted))
+	return nil
+}
+
+func main() {
+	// global flags come before the command
+	flags := flag.NewFlagSet("termquery", flag.ExitOnError)
+	profile := flags.String("profile", "", "connect with this profile instead of default_profile")
+	flags.Parse(os.Args[1:])
+	args := flags.Args()
+	command := ""
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	// The language server owns stdout, so its logs go to stderr instead.
+	languageServer := command == "lsp"
+	var logOutput io.Writer = os.Stdout
+	if languageServer {
+		logOutput = os.Stderr
+	}
 	logger.Init(logger.LoggerConfig{
 		Level:  slog.LevelError,
 		Format: logger.FormatJSON, // or logger.FormatText
+		Output: logOutput,
 	})
 	logger := logger.Get()
 
@@ -153,68 +244,80 @@ func main() {
 	}
 
 	configParams := config.ConfigParams{
-		Logger:        logger,
-		ConfigPath:    cache.GetConfigDir(home, os.Getenv, logger),
-		ReadDirFunc:   os.ReadDir,
-		MkdirFunc:     os.MkdirAll,
-		StatFunc:      os.Stat,
-		WriteFileFunc: os.WriteFile,
-		ReadFileFunc:  os.ReadFile,
+		Logger:               logger,
+		ConfigPath:           cache.GetConfigDir(home, os.Getenv, logger),
+		DatabricksConfigPath: config.DatabricksConfigPath(home, os.Getenv),
+		ReadDirFunc:          os.ReadDir,
+		MkdirFunc:            os.MkdirAll,
+		StatFunc:             os.Stat,
+		WriteFileFunc:        os.WriteFile,
+		ReadFileFunc:         os.ReadFile,
+		GetEnvFunc:           os.Getenv,
+		CommandFunc:          RealCommandFactory,
 	}
 
 	config.InitConfig(configParams)
+	cfg, err := config.Load(configParams)
+	// the config command is how a broken config gets fixed, so it runs on the defaults
+	configCommand := command == "config"
+	if err != nil && configCommand {
+		fmt.Fprintln(os.Stderr, "Invalid config, showing the defaults:", err)
+		cfg = config.Default()
+	} else if err != nil {
+		fmt.Fprintln(os.Stderr, "Invalid config:", err)
+		os.Exit(1)
+	}
+	var overrides []config.Setting
+	if *profile != "" {
+		overrides = append(overrides, config.Setting{Key: "default_profile", Value: *profile, Source: config.SourceFlag, Origin: "--profile"})
+	}
+	if err := cfg.Apply(overrides); err != nil {
+		fmt.Fprintln(os.Stderr, "Invalid flag:", err)
+		os.Exit(1)
+	}
 
 	cacheParams := cache.CacheParams{
 		Logger:           logger,
 		CachePath:        cache.GetCacheDir(home, os.Getenv, logger),
-		MaxNumberQueries: config.GetMaxNumberHistoricalQueries(configParams),
-		Editor:           cache.GetEditor(config.GetForceUseNeovim(configParams), os.Getenv, logger),
+		MaxNumberQueries: int16(cfg.MaxNumberHistoricalQueries),
+		Editor:           cache.GetEditor(cfg.ForceUseNeovim, os.Getenv, logger),
 		RemoveFunc:       os.Remove,
 		CommandFunc:      RealCommandFactory,
 		ReadDirFunc:      os.ReadDir,
 		MkdirFunc:        os.MkdirAll,
 		StatFunc:         os.Stat,
+		WriteFileFunc:    os.WriteFile,
+		ReadFileFunc:     os.ReadFile,
 	}
 
-	token, err := config.GetToken(configParams, config.GetDefaultProfile(configParams))
-	if err != nil {
-		panic(err)
-	}
-	httpPath, err := config.GetHttpPath(configParams, config.GetDefaultProfile(configParams))
-	if err != nil {
-		panic(err)
-	}
-	ServerHostname, err := config.GetServerHostname(configParams, config.GetDefaultProfile(configParams))
-	if err != nil {
-		panic(err)
-	}
+	cache.InitCache(cacheParams)
 
-	connection := sql.DatabricksConnection{
-		AccessToken:    token,
-		HttpPath:       httpPath,
-		ServerHostname: ServerHostname,
-		Logger:         logger,
+	// a broken keymap is reported up front rather than once the results are shown
+	keys, err := sql.NewKeyMap(cfg.Keymap)
+	if err != nil && !configCommand {
+		fmt.Fprintln(os.Stderr, "Invalid keymap:", err)
+		os.Exit(1)
 	}
 
-	cache.InitCache(cacheParams)
-	queue, _ := cache.CreateFileQueue(cacheParams)
-
-	// file_name := cache.CreateAndEnque(queue, cacheParams, cache.EditFile)
-	file_name := cache.EditMostRecentFile(queue, cacheParams, cache.EditFile)
-	var wg sync.WaitGroup
-	wg.Add(1)
-	go RunQueryFromFileWithChannel(cacheParams, file_name, connection, &wg, logger, rowChan, colChan, errorChan, spinnerFinished)
-	model := initialModel(spinnerFinished)
-	p := tea.NewProgram(model, tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
-		fmt.Println(err)
+	// the language server has no terminal to colour, nor to ask for its background
+	if !languageServer {
+		if err := applyTheme(cfg); err != nil && !configCommand {
+			fmt.Fprintln(os.Stderr, "Invalid theme:", err)
+			os.Exit(1)
+		}
 	}
 
-	wg.Wait()
-	rows := <-rowChan
-	columns := <-colChan
-	err = <-errorChan
+	app := application{
+		logger:       logger,
+		configParams: configParams,
+		config:       cfg,
+		overrides:    overrides,
+		cacheParams:  cacheParams,
+		keys:         keys,
+	}
 
-	// sql.PrintRowsAsTableBasic(os.Stdout, rows)
-	sql.PrintRowsAsTableTea(rows, columns)
+	if err := app.run(args); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"example.com/termquery/config"
+	"example.com/termquery/sql"
+	"example.com/termquery/theme"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var formLabelStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Dark.Accent)).Bold(true)
+var formErrorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Dark.Error))
+var formHelpStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(theme.Dark.Muted))
+
+// tokenSources are the ways the form offers of giving a profile its access
+// token. The ones that keep the token itself out of the profiles file come
+// first; a token typed in is masked like the client secret.
+var tokenSources = []struct {
+	key, label, placeholder string
+}{
+	{"access_token_cmd", "Command printing the access token", "pass show databricks/dev"},
+	{"access_token_file", "File holding the access token", "~/.config/termquery/dev-token"},
+	{"access_token_env", "Environment variable holding the access token", "DATABRICKS_TOKEN"},
+	{"access_token", "Access token", "dapi…"},
+}
+
+// Fields of the form, in the order they are asked for.
+const (
+	fieldName = iota
+	fieldHostname
+	fieldHTTPPath
+	fieldAuthType
+	fieldTokenSource
+	fieldToken
+	fieldClientID
+	fieldClientSecret
+)
+
+// profileForm asks for the settings of a new profile, one field at a time.
+// The auth type and token source are choices made with ←/→ rather than
+// typed, and only the fields the chosen auth type uses are shown.
+type profileForm struct {
+	labels    []string
+	inputs    []textinput.Model
+	options   map[int][]string // the options of the choice fields
+	chosen    map[int]int
+	focus     int
+	err       string
+	submitted bool
+}
+
+func newProfileForm(name string) profileForm {
+	f := profileForm{
+		labels: []string{"Name", "Server hostname", "HTTP path", "Auth type", "Access token from", "", "Client ID", "Client secret"},
+		options: map[int][]string{
+			fieldAuthType:    {sql.AuthPAT, sql.AuthOAuthM2M, sql.AuthOAuthU2M},
+			fieldTokenSource: {},
+		},
+		chosen: map[int]int{},
+	}
+	for _, source := range tokenSources {
+		f.options[fieldTokenSource] = append(f.options[fieldTokenSource], source.key)
+	}
+	placeholders := []string{"dev", "adb-1234567890.12.azuredatabricks.net", "/sql/1.0/warehouses/abc123", "", "", "", "", ""}
+	for i := range f.labels {
+		input := textinput.New()
+		input.Placeholder = placeholders[i]
+		f.inputs = append(f.inputs, input)
+	}
+	f.inputs[fieldName].SetValue(name)
+	f.inputs[fieldClientSecret].EchoMode = textinput.EchoPassword
+	f.inputs[fieldClientSecret].EchoCharacter = '•'
+	f.inputs[fieldToken].EchoCharacter = '•'
+	f.choose(fieldTokenSource, 0)
+	// a name given on the command line needn't be asked for again
+	if name != "" {
+		f.focus = fieldHostname
+	}
+	f.inputs[f.focus].Focus()
+	return f
+}
+
+// choose picks option i of a choice field. The token field is labelled after
+// the token source, and masked when it holds the token itself.
+func (f *profileForm) choose(field int, i int) {
+	n := len(f.options[field])
+	f.chosen[field] = (i + n) % n
+	if field == fieldTokenSource {
+		source := tokenSources[f.chosen[field]]
+		f.labels[fieldToken] = source.label
+		f.inputs[fieldToken].Placeholder = source.placeholder
+		f.inputs[fieldToken].EchoMode = textinput.EchoNormal
+		if source.key == "access_token" {
+			f.inputs[fieldToken].EchoMode = textinput.EchoPassword
+		}
+	}
+}
+
+func (f profileForm) authType() string {
+	return f.options[fieldAuthType][f.chosen[fieldAuthType]]
+}
+
+// shown tells whether the chosen auth type uses a field.
+func (f profileForm) shown(field int) bool {
+	switch field {
+	case fieldTokenSource, fieldToken:
+		return f.authType() == sql.AuthPAT
+	case fieldClientID, fieldClientSecret:
+		return f.authType() == sql.AuthOAuthM2M
+	}
+	return true
+}
+
+// last is the last field shown, where enter saves the profile.
+func (f profileForm) last() int {
+	last := 0
+	for i := range f.inputs {
+		if f.shown(i) {
+			last = i
+		}
+	}
+	return last
+}
+
+func (f profileForm) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (f profileForm) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			return f, tea.Quit
+		case "shift+tab", "up":
+			return f, f.move(-1)
+		case "tab", "down":
+			return f, f.move(1)
+		case "enter":
+			if f.focus < f.last() {
+				return f, f.move(1)
+			}
+			if missing := f.missing(); missing != "" {
+				f.err = missing + " is required"
+				return f, nil
+			}
+			f.submitted = true
+			return f, tea.Quit
+		}
+		if _, ok := f.options[f.focus]; ok {
+			switch msg.String() {
+			case "left", "h":
+				f.choose(f.focus, f.chosen[f.focus]-1)
+			case "right", "l", " ":
+				f.choose(f.focus, f.chosen[f.focus]+1)
+			}
+			return f, nil
+		}
+	}
+	var cmd tea.Cmd
+	f.inputs[f.focus], cmd = f.inputs[f.focus].Update(msg)
+	return f, cmd
+}
+
+// move focuses the next field shown in the direction of delta.
+func (f *profileForm) move(delta int) tea.Cmd {
+	f.inputs[f.focus].Blur()
+	for {
+		f.focus = (f.focus + delta + len(f.inputs)) % len(f.inputs)
+		if f.shown(f.focus) {
+			break
+		}
+	}
+	f.err = ""
+	return f.inputs[f.focus].Focus()
+}
+
+// missing names the first field shown that was left empty, if any.
+func (f profileForm) missing() string {
+	for i, input := range f.inputs {
+		if _, choice := f.options[i]; !choice && f.shown(i) && strings.TrimSpace(input.Value()) == "" {
+			return f.labels[i]
+		}
+	}
+	return ""
+}
+
+func (f profileForm) profile() config.Profile {
+	value := func(field int) string {
+		return strings.TrimSpace(f.inputs[field].Value())
+	}
+	profile := config.Profile{
+		Name:           value(fieldName),
+		ServerHostname: value(fieldHostname),
+		HttpPath:       value(fieldHTTPPath),
+	}
+	switch f.authType() {
+	case sql.AuthPAT:
+		// pat is the default and needn't be written
+		switch tokenSources[f.chosen[fieldTokenSource]].key {
+		case "access_token_cmd":
+			profile.AccessTokenCmd = value(fieldToken)
+		case "access_token_file":
+			profile.AccessTokenFile = value(fieldToken)
+		case "access_token_env":
+			profile.AccessTokenEnv = value(fieldToken)
+		case "access_token":
+			profile.AccessToken = value(fieldToken)
+		}
+	case sql.AuthOAuthM2M:
+		profile.AuthType = sql.AuthOAuthM2M
+		profile.ClientID = value(fieldClientID)
+		profile.ClientSecret = value(fieldClientSecret)
+	default:
+		profile.AuthType = f.authType()
+	}
+	return profile
+}
+
+func (f profileForm) View() string {
+	var b strings.Builder
+	b.WriteString("\n  New profile\n\n")
+	for i, input := range f.inputs {
+		if !f.shown(i) {
+			continue
+		}
+		value := input.View()
+		if _, ok := f.options[i]; ok {
+			value = f.choice(i)
+		}
+		fmt.Fprintf(&b, "  %s\n  %s\n\n", formLabelStyle.Render(f.labels[i]), value)
+	}
+	if f.err != "" {
+		b.WriteString("  " + formErrorStyle.Render(f.err) + "\n\n")
+	}
+	b.WriteString("  " + formHelpStyle.Render("tab next · shift+tab previous · ←/→ choose · enter save · esc cancel") + "\n")
+	return b.String()
+}
+
+// choice shows the options of a choice field with the chosen one marked.
+func (f profileForm) choice(field int) string {
+	options := make([]string, len(f.options[field]))
+	for i, option := range f.options[field] {
+		if i == f.chosen[field] {
+			options[i] = formLabelStyle.Render("(•) " + option)
+		} else {
+			options[i] = "( ) " + option
+		}
+	}
+	return strings.Join(options, "  ")
+}
+
+// promptProfile asks for a new profile, reporting false if it was cancelled.
+func promptProfile(name string) (config.Profile, bool, error) {
+	model, err := tea.NewProgram(newProfileForm(name)).Run()
+	if err != nil {
+		return config.Profile{}, false, err
+	}
+	form := model.(profileForm)
+	return form.profile(), form.submitted, nil
+}
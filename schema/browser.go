@@ -0,0 +1,428 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+
+	"example.com/termquery/theme"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ─── Tree ─────────────────────────────────────────────────────────────────────
+
+type nodeKind int
+
+const (
+	catalogNode nodeKind = iota
+	schemaNode
+	tableNode
+	columnNode
+)
+
+type node struct {
+	kind     nodeKind
+	name     string
+	column   Column
+	parent   *node
+	children []*node
+	expanded bool
+	loaded   bool
+	loading  bool
+}
+
+func (n *node) depth() int {
+	depth := 0
+	for p := n.parent; p != nil; p = p.parent {
+		depth++
+	}
+	return depth
+}
+
+// table returns the table a table or column node belongs to.
+func (n *node) table() (Table, bool) {
+	owner := n
+	if n.kind == columnNode {
+		owner = n.parent
+	}
+	if owner == nil || owner.kind != tableNode {
+		return Table{}, false
+	}
+	t := Table{
+		Catalog: owner.parent.parent.name,
+		Schema:  owner.parent.name,
+		Name:    owner.name,
+	}
+	for _, child := range owner.children {
+		t.Columns = append(t.Columns, child.column)
+	}
+	return t, true
+}
+
+func newNodes(kind nodeKind, parent *node, names []string) []*node {
+	nodes := make([]*node, len(names))
+	for i, name := range names {
+		nodes[i] = &node{kind: kind, name: name, parent: parent}
+	}
+	return nodes
+}
+
+type childrenLoadedMsg struct {
+	parent   *node
+	children []*node
+	err      error
+}
+
+func loadChildren(source Source, parent *node) tea.Cmd {
+	return func() tea.Msg {
+		if parent == nil {
+			names, err := source.Catalogs()
+			return childrenLoadedMsg{nil, newNodes(catalogNode, nil, names), err}
+		}
+		switch parent.kind {
+		case catalogNode:
+			names, err := source.Schemas(parent.name)
+			return childrenLoadedMsg{parent, newNodes(schemaNode, parent, names), err}
+		case schemaNode:
+			names, err := source.Tables(parent.parent.name, parent.name)
+			return childrenLoadedMsg{parent, newNodes(tableNode, parent, names), err}
+		case tableNode:
+			columns, err := source.Columns(parent.parent.parent.name, parent.parent.name, parent.name)
+			children := make([]*node, len(columns))
+			for i, c := range columns {
+				children[i] = &node{kind: columnNode, name: c.Name, column: c, parent: parent, loaded: true}
+			}
+			return childrenLoadedMsg{parent, children, err}
+		}
+		return nil
+	}
+}
+
+// ─── Keys ─────────────────────────────────────────────────────────────────────
+
+type browserKeyMap struct {
+	Up       key.Binding
+	Down     key.Binding
+	Expand   key.Binding
+	Collapse key.Binding
+	Search   key.Binding
+	Select   key.Binding
+	Quit     key.Binding
+}
+
+func (k browserKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Expand, k.Collapse, k.Search, k.Select, k.Quit}
+}
+
+func (k browserKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Up, k.Down, k.Expand, k.Collapse},
+		{k.Search, k.Select, k.Quit},
+	}
+}
+
+var browserKeys = browserKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "move up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "move down"),
+	),
+	Expand: key.NewBinding(
+		key.WithKeys("enter", " ", "right", "l"),
+		key.WithHelp("↵/l", "expand"),
+	),
+	Collapse: key.NewBinding(
+		key.WithKeys("left", "h"),
+		key.WithHelp("←/h", "collapse"),
+	),
+	Search: key.NewBinding(
+		key.WithKeys("/"),
+		key.WithHelp("/", "search"),
+	),
+	Select: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "select * from table"),
+	),
+	Quit: key.NewBinding(
+		key.WithKeys("q", "ctrl+c"),
+		key.WithHelp("q", "quit"),
+	),
+}
+
+// ─── Model ────────────────────────────────────────────────────────────────────
+
+var defaultHeight = 20
+
+var titleStyle, cursorStyle, typeStyle, errorStyle lipgloss.Style
+
+func init() {
+	SetTheme(theme.Dark)
+}
+
+// SetTheme restyles the schema browser.
+func SetTheme(t theme.Theme) {
+	titleStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(t.Header))
+	cursorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(t.Selected)).Background(lipgloss.Color(t.SelectedBackground))
+	typeStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(t.Muted))
+	errorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(t.Error))
+}
+
+type browserModel struct {
+	source    Source
+	roots     []*node
+	loading   bool
+	visible   []*node
+	cursor    int
+	offset    int
+	height    int
+	searching bool
+	search    textinput.Model
+	help      help.Model
+	selected  *Table
+	err       error
+}
+
+func newBrowserModel(source Source) *browserModel {
+	ti := textinput.New()
+	ti.Prompt = "/"
+	ti.CharLimit = 128
+	return &browserModel{
+		source:  source,
+		loading: true,
+		height:  defaultHeight,
+		search:  ti,
+		help:    help.New(),
+	}
+}
+
+func (m *browserModel) Init() tea.Cmd {
+	return loadChildren(m.source, nil)
+}
+
+// RunSchemaBrowser opens the schema explorer and returns the table the user
+// chose to query, or nil if they quit without choosing one.
+func RunSchemaBrowser(source Source) (*Table, error) {
+	p := tea.NewProgram(newBrowserModel(source), tea.WithAltScreen())
+	final, err := p.Run()
+	if err != nil {
+		return nil, err
+	}
+	return final.(*browserModel).selected, nil
+}
+
+// matches reports whether n or any loaded descendant contains the search term.
+func matches(n *node, term string) bool {
+	if strings.Contains(strings.ToLower(n.name), term) {
+		return true
+	}
+	for _, child := range n.children {
+		if matches(child, term) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *browserModel) refreshVisible() {
+	term := strings.ToLower(m.search.Value())
+	var visible []*node
+	var walk func(nodes []*node)
+	walk = func(nodes []*node) {
+		for _, n := range nodes {
+			if term != "" && !matches(n, term) {
+				continue
+			}
+			visible = append(visible, n)
+			if n.expanded || (term != "" && n.loaded) {
+				walk(n.children)
+			}
+		}
+	}
+	walk(m.roots)
+	m.visible = visible
+	m.moveCursor(0)
+}
+
+func (m *browserModel) moveCursor(delta int) {
+	m.cursor = max(0, min(m.cursor+delta, len(m.visible)-1))
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	} else if m.cursor >= m.offset+m.height {
+		m.offset = m.cursor - m.height + 1
+	}
+}
+
+func (m *browserModel) current() *node {
+	if len(m.visible) == 0 {
+		return nil
+	}
+	return m.visible[m.cursor]
+}
+
+func (m *browserModel) expand(n *node) tea.Cmd {
+	if n == nil || n.kind == columnNode || n.loading {
+		return nil
+	}
+	if n.loaded {
+		n.expanded = !n.expanded
+		m.refreshVisible()
+		return nil
+	}
+	n.loading = true
+	return loadChildren(m.source, n)
+}
+
+func (m *browserModel) collapse(n *node) {
+	if n == nil {
+		return
+	}
+	if n.expanded {
+		n.expanded = false
+	} else if n.parent != nil {
+		for i, v := range m.visible {
+			if v == n.parent {
+				m.cursor = i
+			}
+		}
+		n.parent.expanded = false
+	}
+	m.refreshVisible()
+}
+
+func (m *browserModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = max(1, msg.Height-5)
+		m.moveCursor(0)
+		return m, nil
+
+	case childrenLoadedMsg:
+		if msg.parent == nil {
+			m.loading = false
+		} else {
+			msg.parent.loading = false
+		}
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.err = nil
+		if msg.parent == nil {
+			m.roots = msg.children
+		} else {
+			msg.parent.children = msg.children
+			msg.parent.loaded = true
+			msg.parent.expanded = true
+		}
+		m.refreshVisible()
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.searching {
+			switch msg.String() {
+			case "esc":
+				m.searching = false
+				m.search.Reset()
+				m.search.Blur()
+			case "enter":
+				m.searching = false
+				m.search.Blur()
+			default:
+				var cmd tea.Cmd
+				m.search, cmd = m.search.Update(msg)
+				m.refreshVisible()
+				return m, cmd
+			}
+			m.refreshVisible()
+			return m, nil
+		}
+
+		switch {
+		case key.Matches(msg, browserKeys.Quit):
+			return m, tea.Quit
+		case key.Matches(msg, browserKeys.Up):
+			m.moveCursor(-1)
+		case key.Matches(msg, browserKeys.Down):
+			m.moveCursor(1)
+		case key.Matches(msg, browserKeys.Expand):
+			return m, m.expand(m.current())
+		case key.Matches(msg, browserKeys.Collapse):
+			m.collapse(m.current())
+		case key.Matches(msg, browserKeys.Search):
+			m.searching = true
+			return m, m.search.Focus()
+		case key.Matches(msg, browserKeys.Select):
+			if n := m.current(); n != nil {
+				if t, ok := n.table(); ok {
+					m.selected = &t
+					return m, tea.Quit
+				}
+			}
+		case msg.String() == "esc":
+			m.search.Reset()
+			m.refreshVisible()
+		}
+	}
+	return m, nil
+}
+
+func (m *browserModel) renderNode(n *node) string {
+	indent := strings.Repeat("  ", n.depth())
+	switch {
+	case n.kind == columnNode:
+		line := fmt.Sprintf("%s· %s %s", indent, n.name, typeStyle.Render(n.column.Type))
+		if !n.column.Nullable {
+			line += typeStyle.Render(" not null")
+		}
+		if n.column.Comment != "" {
+			line += typeStyle.Render(" -- " + n.column.Comment)
+		}
+		return line
+	case n.loading:
+		return fmt.Sprintf("%s… %s", indent, n.name)
+	case n.expanded:
+		return fmt.Sprintf("%s▾ %s", indent, n.name)
+	default:
+		return fmt.Sprintf("%s▸ %s", indent, n.name)
+	}
+}
+
+func (m *browserModel) View() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("Schema Browser"))
+	b.WriteString("\n")
+	if m.searching || m.search.Value() != "" {
+		b.WriteString(m.search.View())
+	}
+	b.WriteString("\n")
+
+	switch {
+	case m.loading:
+		b.WriteString("Loading catalogs…\n")
+	case len(m.visible) == 0:
+		b.WriteString("Nothing to show.\n")
+	default:
+		end := min(m.offset+m.height, len(m.visible))
+		for i := m.offset; i < end; i++ {
+			line := m.renderNode(m.visible[i])
+			if i == m.cursor {
+				line = cursorStyle.Render(line)
+			}
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+	}
+
+	if m.err != nil {
+		b.WriteString(errorStyle.Render(m.err.Error()))
+		b.WriteString("\n")
+	}
+	b.WriteString(m.help.View(browserKeys))
+	return b.String()
+}
@@ -0,0 +1,57 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockSource struct{}
+
+func (mockSource) Catalogs() ([]string, error) { return []string{"main", "samples"}, nil }
+func (mockSource) Schemas(catalog string) ([]string, error) {
+	return []string{"sales"}, nil
+}
+func (mockSource) Tables(catalog string, schema string) ([]string, error) {
+	return []string{"orders", "customers"}, nil
+}
+func (mockSource) Columns(catalog string, schema string, table string) ([]Column, error) {
+	return []Column{{Name: "id", Type: "bigint"}, {Name: "amount", Type: "decimal(10,2)", Nullable: true}}, nil
+}
+
+func TestBrowserExpandAndSelect(t *testing.T) {
+	m := newBrowserModel(mockSource{})
+	m.Update(loadChildren(m.source, nil)())
+	assert.Len(t, m.visible, 2)
+
+	m.Update(loadChildren(m.source, m.visible[0])())
+	m.Update(loadChildren(m.source, m.visible[1])())
+	assert.Len(t, m.visible, 5)
+
+	m.cursor = 3
+	table, ok := m.current().table()
+	assert.True(t, ok)
+	assert.Equal(t, "SELECT * FROM main.sales.customers LIMIT 100", table.SelectStatement())
+}
+
+func TestBrowserSearchKeepsAncestors(t *testing.T) {
+	m := newBrowserModel(mockSource{})
+	m.Update(loadChildren(m.source, nil)())
+	m.Update(loadChildren(m.source, m.visible[0])())
+	m.Update(loadChildren(m.source, m.visible[1])())
+
+	m.search.SetValue("ORD")
+	m.refreshVisible()
+
+	names := []string{}
+	for _, n := range m.visible {
+		names = append(names, n.name)
+	}
+	assert.Equal(t, []string{"main", "sales", "orders"}, names)
+}
+
+func TestQuoteIdentifier(t *testing.T) {
+	assert.Equal(t, "orders", QuoteIdentifier("orders"))
+	assert.Equal(t, "`my-table`", QuoteIdentifier("my-table"))
+	assert.Equal(t, "`a``b`", QuoteIdentifier("a`b"))
+}
@@ -0,0 +1,117 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+
+	"example.com/termquery/sql"
+)
+
+func quoteString(value string) string {
+	escaped := strings.ReplaceAll(value, `\`, `\\`)
+	escaped = strings.ReplaceAll(escaped, "'", `\'`)
+	return "'" + escaped + "'"
+}
+
+// columnValues returns the values of the named column, falling back to the
+// first column when the name is empty.
+func columnValues(rows []map[string]string, cols []string, name string) ([]string, error) {
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("query returned no columns")
+	}
+	if name == "" {
+		name = cols[0]
+	}
+	values := make([]string, 0, len(rows))
+	for _, row := range rows {
+		value, ok := row[name]
+		if !ok {
+			return nil, fmt.Errorf("column %s not in result", name)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func ListCatalogs(connection sql.Connection) ([]string, error) {
+	rows, cols, err := connection.RunQuery("SHOW CATALOGS")
+	if err != nil {
+		return nil, err
+	}
+	return columnValues(rows, cols, "")
+}
+
+func ListSchemas(connection sql.Connection, catalog string) ([]string, error) {
+	rows, cols, err := connection.RunQuery("SHOW SCHEMAS IN " + QuoteIdentifier(catalog))
+	if err != nil {
+		return nil, err
+	}
+	return columnValues(rows, cols, "")
+}
+
+func ListTables(connection sql.Connection, catalog string, schema string) ([]string, error) {
+	rows, cols, err := connection.RunQuery("SHOW TABLES IN " + QuoteIdentifier(catalog) + "." + QuoteIdentifier(schema))
+	if err != nil {
+		return nil, err
+	}
+	return columnValues(rows, cols, "tableName")
+}
+
+func ListColumns(connection sql.Connection, catalog string, schema string, table string) ([]Column, error) {
+	query := fmt.Sprintf(
+		"SELECT column_name, data_type, is_nullable, comment FROM %s.information_schema.columns "+
+			"WHERE table_schema = %s AND table_name = %s ORDER BY ordinal_position",
+		QuoteIdentifier(catalog), quoteString(schema), quoteString(table),
+	)
+	rows, _, err := connection.RunQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]Column, 0, len(rows))
+	for _, row := range rows {
+		columns = append(columns, columnFromRow(row))
+	}
+	return columns, nil
+}
+
+func columnFromRow(row map[string]string) Column {
+	comment := row["comment"]
+	if comment == sql.NullValue {
+		comment = ""
+	}
+	return Column{
+		Name:     row["column_name"],
+		Type:     row["data_type"],
+		Nullable: row["is_nullable"] != "NO",
+		Comment:  comment,
+	}
+}
+
+// Source supplies the catalog tree one level at a time.
+type Source interface {
+	Catalogs() ([]string, error)
+	Schemas(catalog string) ([]string, error)
+	Tables(catalog string, schema string) ([]string, error)
+	Columns(catalog string, schema string, table string) ([]Column, error)
+}
+
+// ConnectionSource reads the catalog tree live through a Connection.
+type ConnectionSource struct {
+	Connection sql.Connection
+}
+
+func (s ConnectionSource) Catalogs() ([]string, error) {
+	return ListCatalogs(s.Connection)
+}
+
+func (s ConnectionSource) Schemas(catalog string) ([]string, error) {
+	return ListSchemas(s.Connection, catalog)
+}
+
+func (s ConnectionSource) Tables(catalog string, schema string) ([]string, error) {
+	return ListTables(s.Connection, catalog, schema)
+}
+
+func (s ConnectionSource) Columns(catalog string, schema string, table string) ([]Column, error) {
+	return ListColumns(s.Connection, catalog, schema, table)
+}
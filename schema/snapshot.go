@@ -0,0 +1,184 @@
+package schema
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"example.com/termquery/constants"
+	"example.com/termquery/sql"
+	"example.com/termquery/utils"
+)
+
+const snapshotQuery = "SELECT table_catalog, table_schema, table_name, column_name, data_type, is_nullable, comment " +
+	"FROM system.information_schema.columns " +
+	"ORDER BY table_catalog, table_schema, table_name, ordinal_position"
+
+// Snapshot is a point in time copy of every table and column visible to a profile.
+type Snapshot struct {
+	Profile   string    `json:"profile"`
+	FetchedAt time.Time `json:"fetched_at"`
+	Tables    []Table   `json:"tables"`
+}
+
+type SnapshotParams struct {
+	Logger        *slog.Logger
+	CachePath     string
+	Profile       string
+	TTL           time.Duration
+	NowFunc       func() time.Time
+	ReadFileFunc  utils.ReadFileFunc
+	WriteFileFunc utils.WriteFileFunc
+	MkdirFunc     utils.MkdirFunc
+}
+
+func SnapshotPath(params SnapshotParams) string {
+	return filepath.Join(params.CachePath, constants.SchemaCacheDirectory, params.Profile+".json")
+}
+
+func ReadSnapshot(params SnapshotParams) (Snapshot, error) {
+	data, err := params.ReadFileFunc(SnapshotPath(params))
+	if err != nil {
+		return Snapshot{}, err
+	}
+	var snapshot Snapshot
+	err = json.Unmarshal(data, &snapshot)
+	return snapshot, err
+}
+
+func WriteSnapshot(params SnapshotParams, snapshot Snapshot) error {
+	err := params.MkdirFunc(filepath.Dir(SnapshotPath(params)), os.ModePerm)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	return params.WriteFileFunc(SnapshotPath(params), data, 0644)
+}
+
+func (s Snapshot) IsStale(ttl time.Duration, now time.Time) bool {
+	return now.Sub(s.FetchedAt) > ttl
+}
+
+// FetchSnapshot reads every column from information_schema in a single query.
+func FetchSnapshot(connection sql.Connection, profile string, now time.Time) (Snapshot, error) {
+	rows, _, err := connection.RunQuery(snapshotQuery)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	snapshot := Snapshot{Profile: profile, FetchedAt: now}
+	for _, row := range rows {
+		last := len(snapshot.Tables) - 1
+		if last < 0 ||
+			snapshot.Tables[last].Catalog != row["table_catalog"] ||
+			snapshot.Tables[last].Schema != row["table_schema"] ||
+			snapshot.Tables[last].Name != row["table_name"] {
+			snapshot.Tables = append(snapshot.Tables, Table{
+				Catalog: row["table_catalog"],
+				Schema:  row["table_schema"],
+				Name:    row["table_name"],
+			})
+			last++
+		}
+		snapshot.Tables[last].Columns = append(snapshot.Tables[last].Columns, columnFromRow(row))
+	}
+	return snapshot, nil
+}
+
+// RefreshSnapshot fetches a new snapshot and persists it under the cache directory.
+func RefreshSnapshot(connection sql.Connection, params SnapshotParams) (Snapshot, error) {
+	snapshot, err := FetchSnapshot(connection, params.Profile, params.NowFunc())
+	if err != nil {
+		return Snapshot{}, err
+	}
+	return snapshot, WriteSnapshot(params, snapshot)
+}
+
+// LoadSnapshot returns the cached snapshot, refreshing it once it is older
+// than the TTL. A stale snapshot is still returned if the refresh fails so
+// that lookups keep working while disconnected.
+func LoadSnapshot(connection sql.Connection, params SnapshotParams) (Snapshot, error) {
+	cached, err := ReadSnapshot(params)
+	if err == nil && !cached.IsStale(params.TTL, params.NowFunc()) {
+		return cached, nil
+	}
+	fresh, refreshErr := RefreshSnapshot(connection, params)
+	if refreshErr == nil {
+		return fresh, nil
+	}
+	if err == nil {
+		params.Logger.Warn("Using stale schema snapshot", "profile", params.Profile, "error", refreshErr)
+		return cached, nil
+	}
+	return Snapshot{}, refreshErr
+}
+
+// FindTable looks up a table by a one, two or three part name, ignoring case
+// and backticks.
+func (s Snapshot) FindTable(name string) (Table, bool) {
+	parts := strings.Split(strings.ToLower(strings.ReplaceAll(name, "`", "")), ".")
+	for _, t := range s.Tables {
+		full := []string{strings.ToLower(t.Catalog), strings.ToLower(t.Schema), strings.ToLower(t.Name)}
+		if len(parts) <= len(full) && slices.Equal(parts, full[len(full)-len(parts):]) {
+			return t, true
+		}
+	}
+	return Table{}, false
+}
+
+// ─── Source ───────────────────────────────────────────────────────────────────
+
+func appendUnique(values []string, value string) []string {
+	if len(values) > 0 && values[len(values)-1] == value {
+		return values
+	}
+	return append(values, value)
+}
+
+// SnapshotSource serves the catalog tree from a cached snapshot.
+type SnapshotSource struct {
+	Snapshot Snapshot
+}
+
+func (s SnapshotSource) Catalogs() ([]string, error) {
+	var catalogs []string
+	for _, t := range s.Snapshot.Tables {
+		catalogs = appendUnique(catalogs, t.Catalog)
+	}
+	return catalogs, nil
+}
+
+func (s SnapshotSource) Schemas(catalog string) ([]string, error) {
+	var schemas []string
+	for _, t := range s.Snapshot.Tables {
+		if t.Catalog == catalog {
+			schemas = appendUnique(schemas, t.Schema)
+		}
+	}
+	return schemas, nil
+}
+
+func (s SnapshotSource) Tables(catalog string, schema string) ([]string, error) {
+	var tables []string
+	for _, t := range s.Snapshot.Tables {
+		if t.Catalog == catalog && t.Schema == schema {
+			tables = append(tables, t.Name)
+		}
+	}
+	return tables, nil
+}
+
+func (s SnapshotSource) Columns(catalog string, schema string, table string) ([]Column, error) {
+	for _, t := range s.Snapshot.Tables {
+		if t.Catalog == catalog && t.Schema == schema && t.Name == table {
+			return t.Columns, nil
+		}
+	}
+	return nil, nil
+}
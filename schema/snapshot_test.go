@@ -0,0 +1,99 @@
+package schema
+
+import (
+	gosql "database/sql"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockConnection struct {
+	rows []map[string]string
+	err  error
+}
+
+func (m mockConnection) Query(sqlString string) (*gosql.Rows, error) { return nil, nil }
+func (m mockConnection) RunQuery(sqlString string) ([]map[string]string, []string, error) {
+	return m.rows, nil, m.err
+}
+func (m mockConnection) RunQueryFromFile(filePath string) ([]map[string]string, []string, map[string]string, error) {
+	return m.rows, nil, nil, m.err
+}
+
+func newMemoryParams(files map[string][]byte, now time.Time) SnapshotParams {
+	return SnapshotParams{
+		Logger:    slog.Default(),
+		CachePath: "cache",
+		Profile:   "dev",
+		TTL:       time.Hour,
+		NowFunc:   func() time.Time { return now },
+		ReadFileFunc: func(name string) ([]byte, error) {
+			data, ok := files[name]
+			if !ok {
+				return nil, os.ErrNotExist
+			}
+			return data, nil
+		},
+		WriteFileFunc: func(name string, data []byte, perm os.FileMode) error {
+			files[name] = data
+			return nil
+		},
+		MkdirFunc: func(path string, perm os.FileMode) error { return nil },
+	}
+}
+
+var columnRows = []map[string]string{
+	{"table_catalog": "main", "table_schema": "sales", "table_name": "orders", "column_name": "id", "data_type": "bigint", "is_nullable": "NO", "comment": "<nil>"},
+	{"table_catalog": "main", "table_schema": "sales", "table_name": "orders", "column_name": "amount", "data_type": "double", "is_nullable": "YES", "comment": "gross"},
+	{"table_catalog": "main", "table_schema": "sales", "table_name": "customers", "column_name": "id", "data_type": "bigint", "is_nullable": "NO", "comment": "<nil>"},
+}
+
+func TestFetchSnapshotGroupsColumns(t *testing.T) {
+	snapshot, err := FetchSnapshot(mockConnection{rows: columnRows}, "dev", time.Now())
+
+	assert.Nil(t, err)
+	assert.Len(t, snapshot.Tables, 2)
+	assert.Equal(t, []Column{{"id", "bigint", false, ""}, {"amount", "double", true, "gross"}}, snapshot.Tables[0].Columns)
+}
+
+func TestLoadSnapshotUsesFreshCache(t *testing.T) {
+	now := time.Now()
+	files := map[string][]byte{}
+	params := newMemoryParams(files, now)
+	_, err := RefreshSnapshot(mockConnection{rows: columnRows}, params)
+	assert.Nil(t, err)
+
+	snapshot, err := LoadSnapshot(mockConnection{rows: nil}, params)
+
+	assert.Nil(t, err)
+	assert.Len(t, snapshot.Tables, 2)
+}
+
+func TestLoadSnapshotFallsBackToStaleWhenOffline(t *testing.T) {
+	now := time.Now()
+	files := map[string][]byte{}
+	params := newMemoryParams(files, now.Add(-2*time.Hour))
+	_, err := RefreshSnapshot(mockConnection{rows: columnRows}, params)
+	assert.Nil(t, err)
+
+	params.NowFunc = func() time.Time { return now }
+	snapshot, err := LoadSnapshot(mockConnection{err: os.ErrDeadlineExceeded}, params)
+
+	assert.Nil(t, err)
+	assert.True(t, snapshot.IsStale(params.TTL, now))
+	assert.Len(t, snapshot.Tables, 2)
+}
+
+func TestFindTable(t *testing.T) {
+	snapshot, _ := FetchSnapshot(mockConnection{rows: columnRows}, "dev", time.Now())
+
+	table, ok := snapshot.FindTable("sales.Orders")
+	assert.True(t, ok)
+	assert.Equal(t, "orders", table.Name)
+
+	_, ok = snapshot.FindTable("other.orders")
+	assert.False(t, ok)
+}
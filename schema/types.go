@@ -0,0 +1,40 @@
+package schema
+
+import (
+	"regexp"
+	"strings"
+)
+
+type Column struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Nullable bool   `json:"nullable"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+type Table struct {
+	Catalog string   `json:"catalog"`
+	Schema  string   `json:"schema"`
+	Name    string   `json:"name"`
+	Columns []Column `json:"columns"`
+}
+
+var plainIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// QuoteIdentifier wraps name in backticks unless it is a plain identifier.
+func QuoteIdentifier(name string) string {
+	if plainIdentifier.MatchString(name) {
+		return name
+	}
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// QualifiedName returns the three part catalog.schema.table name of t.
+func (t Table) QualifiedName() string {
+	return QuoteIdentifier(t.Catalog) + "." + QuoteIdentifier(t.Schema) + "." + QuoteIdentifier(t.Name)
+}
+
+// SelectStatement returns a starter query for browsing the contents of t.
+func (t Table) SelectStatement() string {
+	return "SELECT * FROM " + t.QualifiedName() + " LIMIT 100"
+}
@@ -0,0 +1,210 @@
+package sql
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/evertras/bubble-table/table"
+)
+
+// countColumn names the column of group sizes "count", unless a group column
+// already has that name.
+func countColumn(groupCols []string) string {
+	name := "count"
+	for i := 2; slices.Contains(groupCols, name); i++ {
+		name = fmt.Sprintf("count_%d", i)
+	}
+	return name
+}
+
+// aggregateGroup is one combination of group-by values and the rows that have it.
+type aggregateGroup struct {
+	values Record
+	rows   []Record
+}
+
+// groupRows groups rows by the values of cols, largest groups first.
+func groupRows(rows []Record, cols []string) []aggregateGroup {
+	var groups []aggregateGroup
+	index := map[string]int{}
+	for _, r := range rows {
+		values := make([]string, len(cols))
+		for i, c := range cols {
+			values[i] = r[c]
+		}
+		// the unit separator can't be confused with anything in a value
+		key := strings.Join(values, "\x1f")
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			group := aggregateGroup{values: Record{}}
+			for _, c := range cols {
+				group.values[c] = r[c]
+			}
+			groups = append(groups, group)
+		}
+		groups[i].rows = append(groups[i].rows, r)
+	}
+	slices.SortStableFunc(groups, func(a, b aggregateGroup) int {
+		return len(b.rows) - len(a.rows)
+	})
+	return groups
+}
+
+// aggregateFunctions are computed for the measures of each kind. Other kinds
+// are only counted.
+var aggregateFunctions = map[valueKind][]string{
+	kindNumber: {"sum", "avg", "min", "max"},
+	kindTime:   {"min", "max"},
+}
+
+// aggregateColumns names the result columns: the group columns, the count
+// and then e.g. "sum(amount)" for every measure.
+func aggregateColumns(groupCols []string, measures []string, kinds map[string]valueKind) []string {
+	cols := append(slices.Clone(groupCols), countColumn(groupCols))
+	for _, c := range measures {
+		for _, f := range aggregateFunctions[kinds[c]] {
+			cols = append(cols, fmt.Sprintf("%s(%s)", f, c))
+		}
+	}
+	return cols
+}
+
+// aggregate summarises each group as one record with the columns of aggregateColumns.
+func aggregate(groups []aggregateGroup, groupCols []string, measures []string, kinds map[string]valueKind) []Record {
+	count := countColumn(groupCols)
+	out := make([]Record, len(groups))
+	for i, g := range groups {
+		r := Record{count: strconv.Itoa(len(g.rows))}
+		for c, v := range g.values {
+			r[c] = v
+		}
+		for _, c := range measures {
+			for f, v := range aggregateValues(g.rows, c, kinds[c]) {
+				r[fmt.Sprintf("%s(%s)", f, c)] = v
+			}
+		}
+		out[i] = r
+	}
+	return out
+}
+
+// aggregateValues computes the aggregateFunctions of one column, ignoring NULLs.
+func aggregateValues(rows []Record, col string, kind valueKind) map[string]string {
+	values := map[string]string{}
+	if len(aggregateFunctions[kind]) == 0 {
+		return values
+	}
+	var minValue, maxValue string
+	sum, n := 0.0, 0
+	for _, r := range rows {
+		v := r[col]
+		if isNull(v) {
+			continue
+		}
+		if n == 0 || compareValues(v, minValue, kind) < 0 {
+			minValue = v
+		}
+		if n == 0 || compareValues(v, maxValue, kind) > 0 {
+			maxValue = v
+		}
+		if f, ok := parseNumber(v); ok {
+			sum += f
+		}
+		n++
+	}
+	if n == 0 {
+		for _, f := range aggregateFunctions[kind] {
+			values[f] = NullValue
+		}
+		return values
+	}
+	values["min"] = minValue
+	values["max"] = maxValue
+	if kind == kindNumber {
+		values["sum"] = formatAggregate(sum)
+		values["avg"] = formatAggregate(sum / float64(n))
+	}
+	return values
+}
+
+func formatAggregate(n float64) string {
+	return strconv.FormatFloat(math.Round(n*1e6)/1e6, 'f', -1, 64)
+}
+
+// aggregateView shows the groups as a table that can be drilled into.
+type aggregateView struct {
+	groupCols []string
+	groups    []aggregateGroup
+	cols      []string
+	records   []Record
+	table     table.Model
+}
+
+// newAggregateView groups rows by groupCols and aggregates the other visible columns.
+func newAggregateView(rows []Record, groupCols []string, visibleCols []string, kinds map[string]valueKind) aggregateView {
+	var measures []string
+	for _, c := range visibleCols {
+		if !slices.Contains(groupCols, c) {
+			measures = append(measures, c)
+		}
+	}
+	groups := groupRows(rows, groupCols)
+	return aggregateView{
+		groupCols: groupCols,
+		groups:    groups,
+		cols:      aggregateColumns(groupCols, measures, kinds),
+		records:   aggregate(groups, groupCols, measures, kinds),
+	}
+}
+
+func (v *aggregateView) build(width int, pageSize int) {
+	cols := make([]table.Column, len(v.cols))
+	for i, c := range v.cols {
+		cols[i] = table.NewColumn(c, c, autoColumnWidth(v.records, c))
+	}
+	rows := make([]table.Row, len(v.records))
+	for i, r := range v.records {
+		rd := table.RowData{}
+		for _, c := range v.cols {
+			rd[c] = r[c]
+		}
+		rows[i] = table.NewRow(rd)
+	}
+	v.table = table.New(cols).
+		WithRows(rows).
+		WithMaxTotalWidth(width).
+		WithPageSize(pageSize).
+		WithHorizontalFreezeColumnCount(len(v.groupCols)).
+		Focused(true).
+		WithBaseStyle(borderStyle).
+		HeaderStyle(headerStyle).
+		HighlightStyle(highlightStyle)
+}
+
+// selected is the group under the cursor.
+func (v aggregateView) selected() (aggregateGroup, bool) {
+	if len(v.groups) == 0 {
+		return aggregateGroup{}, false
+	}
+	return v.groups[v.table.GetHighlightedRowIndex()], true
+}
+
+// describe names a group, e.g. "country=NL, city=Utrecht".
+func (v aggregateView) describe(g aggregateGroup) string {
+	parts := make([]string, len(v.groupCols))
+	for i, c := range v.groupCols {
+		parts[i] = c + "=" + g.values[c]
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (v aggregateView) View(keys navigationKeyMap) string {
+	footer := fmt.Sprintf("%d groups by %s · %s · %s · %s", len(v.groups), strings.Join(v.groupCols, ", "),
+		keyHint(keys.Record, "show rows"), keyHint(keys.GroupBy, "regroup"), keyHint(keys.Back, "back"))
+	return v.table.View() + "\n" + statusStyle.Render(footer)
+}
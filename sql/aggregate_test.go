@@ -0,0 +1,65 @@
+package sql
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregate(t *testing.T) {
+	rows := []Record{
+		{"city": "Utrecht", "amount": "10", "seen": "2024-01-02"},
+		{"city": "Leiden", "amount": "4", "seen": "2024-03-01"},
+		{"city": "Utrecht", "amount": NullValue, "seen": "2023-12-31"},
+		{"city": "Utrecht", "amount": "2.5", "seen": NullValue},
+		{"city": "Leiden", "amount": "1", "seen": "2024-02-01"},
+		{"city": NullValue, "amount": "7", "seen": "2024-02-01"},
+	}
+	kinds := map[string]valueKind{"city": kindString, "amount": kindNumber, "seen": kindTime}
+
+	view := newAggregateView(rows, []string{"city"}, []string{"city", "amount", "seen"}, kinds)
+
+	assert.Equal(t, []string{"city", "count", "sum(amount)", "avg(amount)", "min(amount)", "max(amount)", "min(seen)", "max(seen)"}, view.cols)
+	assert.Equal(t, []Record{
+		{"city": "Utrecht", "count": "3", "sum(amount)": "12.5", "avg(amount)": "6.25", "min(amount)": "2.5", "max(amount)": "10", "min(seen)": "2023-12-31", "max(seen)": "2024-01-02"},
+		{"city": "Leiden", "count": "2", "sum(amount)": "5", "avg(amount)": "2.5", "min(amount)": "1", "max(amount)": "4", "min(seen)": "2024-02-01", "max(seen)": "2024-03-01"},
+		{"city": NullValue, "count": "1", "sum(amount)": "7", "avg(amount)": "7", "min(amount)": "7", "max(amount)": "7", "min(seen)": "2024-02-01", "max(seen)": "2024-02-01"},
+	}, view.records)
+	assert.Len(t, view.groups[0].rows, 3)
+}
+
+func TestAggregateCountDoesNotHideGroupColumn(t *testing.T) {
+	rows := []Record{
+		{"count": "3", "count_2": "a"},
+		{"count": "3", "count_2": "b"},
+		{"count": "5", "count_2": "a"},
+	}
+	kinds := map[string]valueKind{"count": kindNumber, "count_2": kindString}
+
+	view := newAggregateView(rows, []string{"count", "count_2"}, []string{"count", "count_2"}, kinds)
+
+	assert.Equal(t, []string{"count", "count_2", "count_3"}, view.cols)
+	assert.Equal(t, Record{"count": "3", "count_2": "a", "count_3": "1"}, view.records[0])
+}
+
+func TestDrillIntoGroup(t *testing.T) {
+	data := []map[string]string{
+		{"city": "Utrecht", "n": "1"},
+		{"city": "Leiden", "n": "2"},
+		{"city": "Utrecht", "n": "3"},
+	}
+	m := NewModel(data, []string{"city", "n"}, nil, QueryContext{}, DefaultKeyMap())
+	m.aggregate = newAggregateView(m.filteredRows, []string{"city"}, m.visibleCols, m.kinds)
+	m.aggregate.build(m.width, m.pageSize())
+	m.state = stateAggregate
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, stateNavigation, m.state)
+	assert.Equal(t, []string{"1", "3"}, column(m.filteredRows, "n"))
+	assert.Contains(t, m.statusLine(), "city=Utrecht")
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Equal(t, stateAggregate, m.state)
+	assert.Len(t, m.filteredRows, 3)
+}
@@ -7,20 +7,26 @@ import (
 	"slices"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/evertras/bubble-table/table"
-	"golang.org/x/term"
 )
 
 const textInputWidth = 50
 const filterColumnWidth = 50
-const tableColumnWidth = 20
+
+// the size we lay out for until the first tea.WindowSizeMsg arrives
+const defaultWidth = 120
+const defaultHeight = 40
+
+// tableChrome is the number of lines the table draws around its rows: the
+// borders, the header and the page footer.
+const tableChrome = 6
 
 // TODO: a better method of state managements
-// TODO: I want to be able to see when filter is applied or not for example
 // TODO: Refactor some of this rubbish code
 // TODO: Also visual mode & Copying visible results?
 // ─── Types & Constants ─────────────────────────────────────────────────────────
@@ -29,13 +35,55 @@ type Record = map[string]string
 
 type viewState int
 
+type filterMode int
+
+const (
+	substringFilter filterMode = iota
+	regexFilter
+	expressionFilter
+)
+
+func (f filterMode) String() string {
+	switch f {
+	case regexFilter:
+		return "regex"
+	case expressionFilter:
+		return "expression"
+	}
+	return "substring"
+}
+
 const (
 	stateFiltering viewState = iota
 	stateSelectVisibleColumns
 	stateSelectFilterColumns
 	stateNavigation
+	statePlan
+	stateStats
+	stateRecord
+	stateSelectGroupColumns
+	stateAggregate
+	stateChart
+	stateSearching
 )
 
+// QueryContext describes where the displayed rows came from so the viewer can
+// go back to the warehouse, e.g. to explain the query.
+type QueryContext struct {
+	Connection Connection
+	Query      string
+	// Layout is the column layout to start with and SaveLayout is called
+	// whenever the user changes it.
+	Layout     ColumnLayout
+	SaveLayout func(ColumnLayout)
+}
+
+type planLoadedMsg struct {
+	plan string
+	cost string
+	err  error
+}
+
 func newCustomDelegate() list.DefaultDelegate {
 	return list.DefaultDelegate{
 		ShowDescription: false,
@@ -64,21 +112,49 @@ func (i columnItem) FilterValue() string {
 
 type model struct {
 	state        viewState
-	regexMode    bool // true when treating input as regex
+	filterMode   filterMode
+	filterErr    error // why the current input could not be applied
 	allCols      []string
 	visibleCols  []string
 	filterCols   []string
 	allRows      []Record
 	filteredRows []Record
+	kinds        map[string]valueKind
+	sortKeys     []sortKey
+	colCursor    int // index into visibleCols of the current column
+	pinned       map[string]bool
+	pinnedCount  int // pinned columns come first in visibleCols
+	width        int
+	height       int
+	autoWidths   map[string]int
+	widthAdjust  map[string]int
+	fitAll       bool // shrink columns so the table fits without scrolling
+	wrap         bool // wrap long values instead of truncating them
 
 	textInput   textinput.Model
+	searchInput textinput.Model
 	listVisible list.Model
 	listFilter  list.Model
+	listGroup   list.Model
 	table       table.Model
 	help        *helpMenu
 
 	navigationHelp helpMenu
 	filteringHelp  helpMenu
+
+	queryContext QueryContext
+	keys         KeyMap
+	plan         planView
+	planLoading  bool
+	planErr      error
+	stats        columnStats
+	record       recordView
+	aggregate    aggregateView
+	drill        *aggregateGroup // the group whose rows are shown, if any
+	chart        chartView
+	search       string
+	matches      []searchMatch
+	matchIndex   int // index into matches of the current match
 }
 
 func (m *model) Init() tea.Cmd {
@@ -86,26 +162,49 @@ func (m *model) Init() tea.Cmd {
 }
 
 // NewModel constructs initial UI state.
-func NewModel(data []map[string]string, cols []string) *model {
+func NewModel(data []map[string]string, cols []string, types map[string]string, queryContext QueryContext, keys KeyMap) *model {
 	// convert to Record
 	rows := make([]Record, len(data))
 	for i, r := range data {
 		rows[i] = r
 	}
 
+	// comparisons use the result column types, guessing for any we weren't given
+	kinds := make(map[string]valueKind, len(cols))
+	autoWidths := make(map[string]int, len(cols))
+	for _, c := range cols {
+		if t, ok := types[c]; ok && t != "" {
+			kinds[c] = kindOfType(t)
+		} else {
+			kinds[c] = inferKind(rows, c)
+		}
+		autoWidths[c] = autoColumnWidth(rows, c)
+	}
+	cols = orderColumns(cols, queryContext.Layout.Order)
+	pinned := map[string]bool{}
+	for _, c := range queryContext.Layout.Pinned {
+		if slices.Contains(cols, c) {
+			pinned[c] = true
+		}
+	}
+
 	// prepare column‐picker lists
 	del := newCustomDelegate()
 	visibleItems := make([]list.Item, len(cols))
 	filterItems := make([]list.Item, len(cols))
+	groupItems := make([]list.Item, len(cols))
 	for i, c := range cols {
 		visibleItems[i] = columnItem{c, true}
 		filterItems[i] = columnItem{c, true}
+		groupItems[i] = columnItem{c, false}
 	}
 
 	listVisible := list.New(visibleItems, &del, filterColumnWidth, len(visibleItems))
 	listVisible.Title = "Visible Columns"
 	listFilter := list.New(filterItems, &del, filterColumnWidth, len(filterItems))
 	listFilter.Title = "Filter Columns"
+	listGroup := list.New(groupItems, &del, filterColumnWidth, len(groupItems))
+	listGroup.Title = "Group By Columns"
 
 	// text input for filtering
 	ti := textinput.New()
@@ -114,37 +213,59 @@ func NewModel(data []map[string]string, cols []string) *model {
 	ti.CharLimit = 128
 	ti.Width = textInputWidth
 
-	navMenu := newNavigationMenu()
-	filterMenu := newFilteringMenu()
+	si := textinput.New()
+	si.Prompt = "search: "
+	si.CharLimit = 128
+	si.Width = textInputWidth
+
+	// the pickers quit with the same keys as the table
+	for _, l := range []*list.Model{&listVisible, &listFilter, &listGroup} {
+		l.KeyMap.Quit = keys.Navigation.Quit
+		l.AdditionalShortHelpKeys = keys.Columns.ShortHelp
+		l.AdditionalFullHelpKeys = keys.Columns.FullHelp
+	}
+
+	navMenu := newHelpMenu(keys.Navigation)
+	filterMenu := newHelpMenu(keys.Filtering)
 
 	m := &model{
 		state:          stateNavigation,
-		regexMode:      false,
+		filterMode:     substringFilter,
 		allCols:        cols,
-		visibleCols:    slices.Clone(cols),
 		filterCols:     slices.Clone(cols),
 		allRows:        rows,
 		filteredRows:   rows,
+		kinds:          kinds,
+		pinned:         pinned,
+		width:          defaultWidth,
+		height:         defaultHeight,
+		autoWidths:     autoWidths,
+		widthAdjust:    map[string]int{},
 		textInput:      ti,
+		searchInput:    si,
 		listVisible:    listVisible,
 		listFilter:     listFilter,
+		listGroup:      listGroup,
 		help:           &navMenu,
 		navigationHelp: navMenu,
 		filteringHelp:  filterMenu,
+		queryContext:   queryContext,
+		keys:           keys,
 	}
 
+	m.arrangeColumns(cols)
 	m.textInput.Focus()
 	m.buildTable(rows)
 	return m
 }
 
 // PrintRowsAsTableTea starts the interactive TUI.
-func PrintRowsAsTableTea(data []map[string]string, cols []string) {
+func PrintRowsAsTableTea(data []map[string]string, cols []string, types map[string]string, queryContext QueryContext, keys KeyMap) {
 	if len(data) == 0 {
 		fmt.Println("No data to display.")
 		return
 	}
-	p := tea.NewProgram(NewModel(data, cols), tea.WithAltScreen())
+	p := tea.NewProgram(NewModel(data, cols, types, queryContext, keys), tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
 		fmt.Println("Error:", err)
 		os.Exit(1)
@@ -153,67 +274,158 @@ func PrintRowsAsTableTea(data []map[string]string, cols []string) {
 
 // ─── Helpers ──────────────────────────────────────────────────────────────────
 
-var (
-	borderStyle    = lipgloss.NewStyle().BorderStyle(lipgloss.NormalBorder()).BorderForeground(lipgloss.Color("240"))
-	headerStyle    = lipgloss.NewStyle().Bold(true).Underline(true).Foreground(lipgloss.Color("250"))
-	highlightStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57"))
-)
+// pageSize is the number of rows that fit below the input, status and help lines.
+func (m *model) pageSize() int {
+	used := 2 + lipgloss.Height(m.help.View()) + tableChrome
+	return max(1, m.height-used)
+}
 
-func (m *model) buildTable(rows []Record) {
-	w, _, err := term.GetSize(int(os.Stdout.Fd()))
-	if err != nil {
-		w = 120
+// resize lays the viewer out for a terminal of the given size.
+func (m *model) resize(width int, height int) {
+	m.width = width
+	m.height = height
+	m.navigationHelp.help.Width = width
+	m.filteringHelp.help.Width = width
+	m.listVisible.SetSize(min(filterColumnWidth, width), height)
+	m.listFilter.SetSize(min(filterColumnWidth, width), height)
+	m.listGroup.SetSize(min(filterColumnWidth, width), height)
+	m.record.viewport.Width = width
+	m.record.viewport.Height = max(1, height-2)
+	if m.state == stateRecord {
+		m.record.show(m.filteredRows, m.allCols, m.record.index)
+	}
+
+	m.buildTable(m.filteredRows)
+
+	if m.aggregate.groups != nil {
+		row := m.aggregate.table.GetHighlightedRowIndex()
+		m.aggregate.build(width, m.pageSize())
+		m.aggregate.table = m.aggregate.table.WithHighlightedRow(row)
 	}
+}
 
+// typing reports whether keys are going into a text input rather than
+// triggering bindings.
+func (m *model) typing() bool {
+	switch m.state {
+	case stateFiltering, stateSearching:
+		return true
+	case stateSelectVisibleColumns:
+		return m.listVisible.SettingFilter()
+	case stateSelectFilterColumns:
+		return m.listFilter.SettingFilter()
+	case stateSelectGroupColumns:
+		return m.listGroup.SettingFilter()
+	}
+	return false
+}
+
+// sourceRows are the rows the filter applies to: those of the group drilled
+// into, or else every row.
+func (m *model) sourceRows() []Record {
+	if m.drill != nil {
+		return m.drill.rows
+	}
+	return m.allRows
+}
+
+// buildTable shows rows with the current columns, keeping the row cursor
+// where it was as far as the rows allow.
+func (m *model) buildTable(rows []Record) {
+	row := m.table.GetHighlightedRowIndex()
+	widths := m.columnWidths()
 	cols := make([]table.Column, len(m.visibleCols))
 	for i, c := range m.visibleCols {
-		cols[i] = table.NewColumn(c, c, tableColumnWidth)
+		title := c + sortIndicator(m.sortKeys, c)
+		if i == m.colCursor {
+			title = "›" + title
+		}
+		cols[i] = table.NewColumn(c, title, widths[i])
 	}
 
 	tblRows := make([]table.Row, len(rows))
 	for i, r := range rows {
 		rd := table.RowData{}
 		for _, col := range m.visibleCols {
-			rd[col] = r[col]
+			if style, ok := m.matchStyle(i, col, r[col]); ok {
+				rd[col] = table.NewStyledCell(r[col], style)
+			} else if style, ok := m.valueStyle(col, r[col]); ok {
+				rd[col] = table.NewStyledCell(r[col], style)
+			} else {
+				rd[col] = r[col]
+			}
 		}
 		tblRows[i] = table.NewRow(rd)
 	}
 
+	// the table moves its cursor itself, so it needs the configured keys too
+	tableKeys := table.DefaultKeyMap()
+	tableKeys.RowUp = m.keys.Navigation.Up
+	tableKeys.RowDown = m.keys.Navigation.Down
+
+	// the first column stays in view like a row header when none is pinned
+	frozen := max(1, m.pinnedCount)
 	m.table = table.New(cols).
 		WithRows(tblRows).
-		WithMaxTotalWidth(w).
-		WithPageSize(20).
-		WithHorizontalFreezeColumnCount(1).
-		WithMinimumHeight(10).
+		WithKeyMap(tableKeys).
+		WithMaxTotalWidth(m.width).
+		WithMultiline(m.wrap).
+		WithPageSize(m.pageSize()).
+		WithHorizontalFreezeColumnCount(frozen).
 		Focused(true).
 		WithBaseStyle(borderStyle).
 		HeaderStyle(headerStyle).
-		HighlightStyle(highlightStyle)
+		HighlightStyle(highlightStyle).
+		WithHighlightedRow(row)
+
+	// rebuilding resets the horizontal scroll, so bring the current column back into view
+	for range max(0, m.colCursor-frozen) {
+		m.table = m.table.ScrollRight()
+	}
 }
 
-func columnList(l *list.Model, key string) []string {
-	switch key {
-	case " ":
-		idx := l.Index()
+// moveColumnCursor changes the current column, scrolling the table with it.
+func (m *model) moveColumnCursor(delta int) {
+	m.colCursor = max(0, min(m.colCursor+delta, len(m.visibleCols)-1))
+	m.buildTable(m.filteredRows)
+}
+
+func (m *model) currentColumn() string {
+	if len(m.visibleCols) == 0 {
+		return ""
+	}
+	return m.visibleCols[min(m.colCursor, len(m.visibleCols)-1)]
+}
+
+// columnList applies a picker binding to l and returns the selected columns
+// once they are applied.
+func columnList(l *list.Model, keys columnListKeyMap, msg tea.KeyMsg) []string {
+	switch {
+	case key.Matches(msg, keys.Toggle):
+		if l.SelectedItem() == nil {
+			// the filter matches nothing
+			return nil
+		}
+		// the index into all items, which differs from Index while filtered
+		idx := l.GlobalIndex()
 		ci := l.Items()[idx].(columnItem)
 		ci.selected = !ci.selected
 		l.SetItem(idx, ci)
 
-	case "a":
+	case key.Matches(msg, keys.SelectAll, keys.ClearAll):
 		for i := range len(l.Items()) {
 			ci := l.Items()[i].(columnItem)
-			ci.selected = true
+			ci.selected = key.Matches(msg, keys.SelectAll)
 			l.SetItem(i, ci)
 		}
 
-	case "c":
-		for i := range len(l.Items()) {
-			ci := l.Items()[i].(columnItem)
-			ci.selected = false
-			l.SetItem(i, ci)
-		}
+	case key.Matches(msg, keys.MoveUp):
+		moveListItem(l, -1)
+
+	case key.Matches(msg, keys.MoveDown):
+		moveListItem(l, 1)
 
-	case "enter":
+	case key.Matches(msg, keys.Apply):
 		var sel []string
 		for _, it := range l.Items() {
 			if ci := it.(columnItem); ci.selected {
@@ -225,18 +437,22 @@ func columnList(l *list.Model, key string) []string {
 	return nil
 }
 
-// applyFilter updates filteredRows based on current mode and input.
+// applyFilter updates filteredRows based on current mode and input. Input
+// that does not compile leaves the previous rows in place and sets filterErr.
 func (m *model) applyFilter() {
 	p := m.textInput.Value()
-	if m.regexMode {
+	m.filterErr = nil
+	source := m.sourceRows()
+	switch m.filterMode {
+	case regexFilter:
 		// regex
 		re, err := regexp.Compile(p)
 		if err != nil {
-			// invalid—no change
+			m.filterErr = err
 			return
 		}
 		var out []Record
-		for _, r := range m.allRows {
+		for _, r := range source {
 			for _, col := range m.filterCols {
 				if re.MatchString(r[col]) {
 					out = append(out, r)
@@ -245,11 +461,28 @@ func (m *model) applyFilter() {
 			}
 		}
 		m.filteredRows = out
-	} else {
+	case expressionFilter:
+		var out []Record
+		if strings.TrimSpace(p) == "" {
+			out = slices.Clone(source)
+		} else {
+			expr, err := parseFilterExpression(p, m.allCols)
+			if err != nil {
+				m.filterErr = err
+				return
+			}
+			for _, r := range source {
+				if expr.match(r, m.kinds) {
+					out = append(out, r)
+				}
+			}
+		}
+		m.filteredRows = out
+	default:
 		// substring
 		lower := strings.ToLower(p)
 		var out []Record
-		for _, r := range m.allRows {
+		for _, r := range source {
 			if lower == "" {
 				out = append(out, r)
 				continue
@@ -263,79 +496,328 @@ func (m *model) applyFilter() {
 		}
 		m.filteredRows = out
 	}
+	sortRecords(m.filteredRows, m.sortKeys, m.kinds)
+	m.findMatches()
 	m.buildTable(m.filteredRows)
 }
 
+// statusLine summarises the active filter and sort so it is always clear
+// whether the table shows every row.
+func (m *model) statusLine() string {
+	var parts []string
+	if p := m.textInput.Value(); p != "" {
+		parts = append(parts, fmt.Sprintf("filter (%s): %s", m.filterMode, p))
+	} else {
+		parts = append(parts, "no filter")
+	}
+	if m.drill != nil {
+		parts = append(parts, "group: "+m.aggregate.describe(*m.drill))
+	}
+	parts = append(parts, fmt.Sprintf("%d of %d rows", len(m.filteredRows), len(m.sourceRows())))
+	if m.search != "" {
+		parts = append(parts, m.searchStatus())
+	}
+	if len(m.sortKeys) > 0 {
+		var keys []string
+		for _, k := range m.sortKeys {
+			keys = append(keys, k.column+sortIndicator([]sortKey{k}, k.column))
+		}
+		parts = append(parts, "sorted by "+strings.Join(keys, ", "))
+	}
+	return statusStyle.Render(strings.Join(parts, " · "))
+}
+
+// explainQuery fetches the formatted plan of the displayed query in the
+// background, and its statistics to size the exchanges by.
+func (m *model) explainQuery() tea.Cmd {
+	queryContext := m.queryContext
+	return func() tea.Msg {
+		plan, err := Explain(queryContext.Connection, queryContext.Query, ExplainFormatted)
+		if err != nil {
+			return planLoadedMsg{err: err}
+		}
+		// the plan is still worth showing without statistics
+		cost, _ := Explain(queryContext.Connection, queryContext.Query, ExplainCost)
+		return planLoadedMsg{plan, cost, nil}
+	}
+}
+
 // ─── Update & View ────────────────────────────────────────────────────────────
 
 func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	key, isKey := msg.(tea.KeyMsg)
-	k := key.String()
+	keyMsg, isKey := msg.(tea.KeyMsg)
+	k := keyMsg.String()
+	nav := m.keys.Navigation
 
-	// global quit
-	if isKey && (k == "ctrl+c" || k == "q") {
+	// ctrl+c always quits, the quit binding only when it isn't being typed
+	if isKey && (k == "ctrl+c" || (!m.typing() && key.Matches(keyMsg, nav.Quit))) {
 		return m, tea.Quit
 	}
 
+	if msg, ok := msg.(tea.WindowSizeMsg); ok {
+		m.resize(msg.Width, msg.Height)
+		var cmd tea.Cmd
+		m.plan, cmd = m.plan.Update(msg)
+		return m, cmd
+	}
+
+	if msg, ok := msg.(planLoadedMsg); ok {
+		m.planLoading = false
+		m.planErr = msg.err
+		m.plan = newPlanView(msg.plan, msg.cost, max(1, m.height-3))
+		return m, nil
+	}
+
 	switch m.state {
 
 	// ─────────────── substring/regex filtering ───────────────
 	case stateNavigation:
 		if isKey {
-			switch k {
-			case "/":
+			switch {
+			case key.Matches(keyMsg, nav.SubstringFilter):
 				// search in non-regex mode
-				m.regexMode = false
+				m.filterMode = substringFilter
 				m.state = stateFiltering
 				m.applyFilter()
 				return m, nil
-			case "\\":
-				// search in non regex mode
-				m.regexMode = true
+			case key.Matches(keyMsg, nav.RegexFilter):
+				// search in regex mode
+				m.filterMode = regexFilter
 				m.state = stateFiltering
 				m.applyFilter()
 				return m, nil
-			case ".":
+			case key.Matches(keyMsg, nav.ExpressionFilter):
+				// filter with an expression over typed columns
+				m.filterMode = expressionFilter
+				m.state = stateFiltering
+				m.applyFilter()
+				return m, nil
+			case key.Matches(keyMsg, nav.VisibleColumns):
 				m.state = stateSelectVisibleColumns
 				return m, nil
-			case ",":
+			case key.Matches(keyMsg, nav.FilterColumns):
 				m.state = stateSelectFilterColumns
 				return m, nil
-			case "left", "h":
-				m.table = m.table.ScrollLeft()
+			case key.Matches(keyMsg, nav.Left):
+				m.moveColumnCursor(-1)
+				return m, nil
+			case key.Matches(keyMsg, nav.Right):
+				m.moveColumnCursor(1)
+				return m, nil
+			case key.Matches(keyMsg, nav.MoveLeft):
+				m.moveColumn(-1)
+				return m, nil
+			case key.Matches(keyMsg, nav.MoveRight):
+				m.moveColumn(1)
 				return m, nil
-			case "right", "l":
-				m.table = m.table.ScrollRight()
+			case key.Matches(keyMsg, nav.Pin):
+				m.togglePin()
 				return m, nil
-			case "up", "k", "down", "j":
+			case key.Matches(keyMsg, nav.Sort, nav.AddSort):
+				m.sortKeys = toggleSortKey(m.sortKeys, m.currentColumn(), key.Matches(keyMsg, nav.AddSort))
+				m.applyFilter()
+				return m, nil
+			case key.Matches(keyMsg, nav.Up, nav.Down):
 				m.table, _ = m.table.Update(msg)
 				return m, nil
-			case "esc":
+			case key.Matches(keyMsg, nav.Back):
+				if m.drill != nil {
+					// back out to the groups
+					m.drill = nil
+					m.applyFilter()
+					m.state = stateAggregate
+					return m, nil
+				}
+				if m.search != "" {
+					m.search = ""
+					m.searchInput.Reset()
+					m.findMatches()
+					m.buildTable(m.filteredRows)
+					return m, nil
+				}
 				m.table.Focused(true)
 				return m, nil
+			case key.Matches(keyMsg, nav.Search):
+				m.searchInput.SetValue(m.search)
+				m.searchInput.CursorEnd()
+				m.searchInput.Focus()
+				m.state = stateSearching
+				return m, nil
+			case key.Matches(keyMsg, nav.NextMatch):
+				m.jumpToMatch(m.matchIndex + 1)
+				return m, nil
+			case key.Matches(keyMsg, nav.PreviousMatch):
+				m.jumpToMatch(m.matchIndex - 1)
+				return m, nil
+			case key.Matches(keyMsg, nav.GroupBy):
+				m.state = stateSelectGroupColumns
+				return m, nil
+			case key.Matches(keyMsg, nav.Chart):
+				m.chart = newChartView(m.filteredRows, m.currentColumn(), m.visibleCols, m.kinds)
+				m.state = stateChart
+				return m, nil
 			// case "v":
 			// 	m.state = stateVisualSelection
 
-			case "?":
+			case key.Matches(keyMsg, nav.Help):
+				// the full help takes more lines, so the page has to shrink
 				m.help.ToggleFullHelp()
+				m.buildTable(m.filteredRows)
+				return m, nil
+			case key.Matches(keyMsg, nav.Widen):
+				m.adjustColumnWidth(widthStep)
+				return m, nil
+			case key.Matches(keyMsg, nav.Narrow):
+				m.adjustColumnWidth(-widthStep)
+				return m, nil
+			case key.Matches(keyMsg, nav.FitAll):
+				m.fitAll = !m.fitAll
+				m.buildTable(m.filteredRows)
+				return m, nil
+			case key.Matches(keyMsg, nav.Wrap):
+				m.wrap = !m.wrap
+				m.buildTable(m.filteredRows)
+				return m, nil
+			case key.Matches(keyMsg, nav.Record):
+				if len(m.filteredRows) == 0 {
+					return m, nil
+				}
+				m.record = newRecordView(m.width, max(1, m.height-2))
+				m.record.show(m.filteredRows, m.allCols, m.table.GetHighlightedRowIndex())
+				m.state = stateRecord
+				return m, nil
+			case key.Matches(keyMsg, nav.Profile):
+				column := m.currentColumn()
+				m.stats = computeColumnStats(m.filteredRows, column, m.kinds[column])
+				m.state = stateStats
+				return m, nil
+			case key.Matches(keyMsg, nav.Explain):
+				if m.queryContext.Connection == nil || m.queryContext.Query == "" {
+					return m, nil
+				}
+				m.state = statePlan
+				m.planLoading = true
+				m.planErr = nil
+				return m, m.explainQuery()
+			}
+
+		}
+
+	// ─────────────── record view ───────────────
+	case stateRecord:
+		if isKey {
+			switch {
+			case key.Matches(keyMsg, nav.Back):
+				m.table = m.table.WithHighlightedRow(m.record.index)
+				m.state = stateNavigation
+				return m, nil
+			case key.Matches(keyMsg, nav.Left):
+				m.record.show(m.filteredRows, m.allCols, m.record.index-1)
+				return m, nil
+			case key.Matches(keyMsg, nav.Right):
+				m.record.show(m.filteredRows, m.allCols, m.record.index+1)
 				return m, nil
 			}
+		}
+		var cmd tea.Cmd
+		m.record.viewport, cmd = m.record.viewport.Update(msg)
+		return m, cmd
 
+	// ─────────────── chart ───────────────
+	case stateChart:
+		if isKey {
+			switch {
+			case key.Matches(keyMsg, nav.Back):
+				m.state = stateNavigation
+			case key.Matches(keyMsg, nav.NextMeasure):
+				m.chart.nextMeasure()
+			}
 		}
+		return m, nil
+
+	// ─────────────── aggregated groups ───────────────
+	case stateAggregate:
+		if isKey {
+			switch {
+			case key.Matches(keyMsg, nav.Back):
+				m.state = stateNavigation
+				return m, nil
+			case key.Matches(keyMsg, nav.GroupBy):
+				m.state = stateSelectGroupColumns
+				return m, nil
+			case key.Matches(keyMsg, nav.Record):
+				if g, ok := m.aggregate.selected(); ok {
+					m.drill = &g
+					m.state = stateNavigation
+					m.applyFilter()
+				}
+				return m, nil
+			}
+		}
+		var cmd tea.Cmd
+		m.aggregate.table, cmd = m.aggregate.table.Update(msg)
+		return m, cmd
+
+	// ─────────────── pick group columns ──────────────
+	case stateSelectGroupColumns:
+		// keys typed into the list's filter aren't picker actions, and back
+		// clears an applied filter before it leaves the picker
+		typing, filtered := m.listGroup.SettingFilter(), m.listGroup.IsFiltered()
+		var cmd tea.Cmd
+		m.listGroup, cmd = m.listGroup.Update(msg)
+		if isKey && !typing {
+			switch {
+			case key.Matches(keyMsg, nav.Back) && filtered:
+				m.listGroup.ResetFilter()
+			case key.Matches(keyMsg, nav.Back):
+				m.state = stateNavigation
+				return m, nil
+			default:
+				if sel := columnList(&m.listGroup, m.keys.Columns, keyMsg); sel != nil {
+					m.aggregate = newAggregateView(m.filteredRows, sel, m.visibleCols, m.kinds)
+					m.aggregate.build(m.width, m.pageSize())
+					m.state = stateAggregate
+					return m, nil
+				}
+			}
+		}
+		return m, cmd
+
+	// ─────────────── column profile ───────────────
+	case stateStats:
+		if isKey && key.Matches(keyMsg, nav.Back) {
+			m.state = stateNavigation
+		}
+		return m, nil
+
+	// ─────────────── query plan ───────────────
+	case statePlan:
+		if m.planLoading || m.planErr != nil {
+			if isKey && key.Matches(keyMsg, nav.Back) {
+				m.state = stateNavigation
+			}
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.plan, cmd = m.plan.Update(msg)
+		if m.plan.closed {
+			m.state = stateNavigation
+		}
+		return m, cmd
 
 	case stateFiltering:
 		m.textInput.Focus()
 
 		if isKey {
-			switch k {
-			case "esc":
+			switch {
+			case key.Matches(keyMsg, m.keys.Filtering.Exit):
 				m.table.Focused(true)
 				m.textInput.Reset()
 				m.applyFilter()
 				m.state = stateNavigation
 				return m, nil
 
-			case "enter":
+			case key.Matches(keyMsg, m.keys.Filtering.Apply):
 				m.table.Focused(true)
 				m.applyFilter()
 				m.state = stateNavigation
@@ -346,13 +828,43 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.applyFilter()
 		return m, nil
 
+	// ─────────────── search ───────────────
+	case stateSearching:
+		if isKey {
+			switch {
+			case key.Matches(keyMsg, m.keys.Filtering.Exit):
+				m.search = ""
+				m.searchInput.Reset()
+				m.findMatches()
+				m.buildTable(m.filteredRows)
+				m.state = stateNavigation
+				return m, nil
+			case key.Matches(keyMsg, m.keys.Filtering.Apply):
+				m.state = stateNavigation
+				return m, nil
+			}
+		}
+		m.searchInput, _ = m.searchInput.Update(msg)
+		if m.searchInput.Value() != m.search {
+			// jump as the search is typed, like vim's incsearch
+			m.search = m.searchInput.Value()
+			m.matchIndex = 0
+			m.findMatches()
+			m.buildTable(m.filteredRows)
+			m.jumpToFirstMatch()
+		}
+		return m, nil
+
 	// ─────────────── pick visible columns ─────────────
 	case stateSelectVisibleColumns:
+		typing, filtered := m.listVisible.SettingFilter(), m.listVisible.IsFiltered()
 		var cmd tea.Cmd
 		m.listVisible, cmd = m.listVisible.Update(msg)
-		if isKey {
-			switch k {
-			case "esc":
+		if isKey && !typing {
+			switch {
+			case key.Matches(keyMsg, nav.Back) && filtered:
+				m.listVisible.ResetFilter()
+			case key.Matches(keyMsg, nav.Back):
 				m.table.Focused(true)
 				m.textInput.Reset()
 				m.applyFilter()
@@ -360,8 +872,11 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 
 			default:
-				if sel := columnList(&m.listVisible, k); sel != nil {
-					m.visibleCols = sel
+				if sel := columnList(&m.listVisible, m.keys.Columns, keyMsg); sel != nil {
+					m.allCols = listOrder(&m.listVisible)
+					m.arrangeColumns(sel)
+					m.colCursor = min(m.colCursor, max(0, len(sel)-1))
+					m.saveLayout()
 					m.state = stateNavigation
 					m.applyFilter()
 					return m, nil
@@ -372,19 +887,21 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	// ─────────────── pick filter columns ──────────────
 	case stateSelectFilterColumns:
+		typing, filtered := m.listFilter.SettingFilter(), m.listFilter.IsFiltered()
 		var cmd tea.Cmd
 		m.listFilter, cmd = m.listFilter.Update(msg)
-		if isKey {
-
-			switch k {
-			case "esc":
+		if isKey && !typing {
+			switch {
+			case key.Matches(keyMsg, nav.Back) && filtered:
+				m.listFilter.ResetFilter()
+			case key.Matches(keyMsg, nav.Back):
 				m.table.Focused(true)
 				m.textInput.Reset()
 				m.applyFilter()
 				m.state = stateNavigation
 				return m, nil
 			default:
-				if sel := columnList(&m.listFilter, k); sel != nil {
+				if sel := columnList(&m.listFilter, m.keys.Columns, keyMsg); sel != nil {
 					m.filterCols = sel
 					m.state = stateNavigation
 					return m, nil
@@ -398,26 +915,35 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m *model) View() string {
-	mode := "substring"
-	if m.regexMode {
-		mode = "regex"
+	input := fmt.Sprintf("%s [%s]", m.textInput.View(), m.filterMode)
+	if m.filterErr != nil {
+		input += " " + errorStyle.Render(m.filterErr.Error())
 	}
 	switch m.state {
 	case stateFiltering:
 		m.help = &m.filteringHelp
 		return fmt.Sprintf(
-			"%s [%s]\n%s\n%s",
-			m.textInput.View(),
-			mode,
+			"%s\n%s\n%s\n%s",
+			input,
+			m.statusLine(),
+			m.help.View(),
+			m.table.View(),
+		)
+	case stateSearching:
+		m.help = &m.filteringHelp
+		return fmt.Sprintf(
+			"%s\n%s\n%s\n%s",
+			m.searchInput.View(),
+			m.statusLine(),
 			m.help.View(),
 			m.table.View(),
 		)
 	case stateNavigation:
 		m.help = &m.navigationHelp
 		return fmt.Sprintf(
-			"%s [%s]\n%s\n%s",
-			m.textInput.View(),
-			mode,
+			"%s\n%s\n%s\n%s",
+			input,
+			m.statusLine(),
 			m.help.View(),
 			m.table.View(),
 		)
@@ -425,6 +951,24 @@ func (m *model) View() string {
 		return m.listVisible.View()
 	case stateSelectFilterColumns:
 		return m.listFilter.View()
+	case stateSelectGroupColumns:
+		return m.listGroup.View()
+	case stateAggregate:
+		return m.aggregate.View(m.keys.Navigation)
+	case stateChart:
+		return m.chart.View(m.width, m.height, m.keys.Navigation)
+	case stateRecord:
+		return m.record.View(len(m.filteredRows), m.keys.Navigation)
+	case stateStats:
+		return m.stats.View() + "\n" + statusStyle.Render(keyHint(m.keys.Navigation.Back, "to go back"))
+	case statePlan:
+		if m.planLoading {
+			return "Running EXPLAIN…\n"
+		}
+		if m.planErr != nil {
+			return fmt.Sprintf("EXPLAIN failed: %s\n\n%s", m.planErr, keyHint(m.keys.Navigation.Back, "to go back"))
+		}
+		return m.plan.View()
 	}
 	return ""
 }
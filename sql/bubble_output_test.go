@@ -0,0 +1,40 @@
+package sql
+
+import (
+	"fmt"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWindowSizeSetsPageSize(t *testing.T) {
+	data := make([]map[string]string, 100)
+	for i := range data {
+		data[i] = map[string]string{"id": fmt.Sprint(i)}
+	}
+	m := NewModel(data, []string{"id"}, nil, QueryContext{}, DefaultKeyMap())
+
+	m.Update(tea.WindowSizeMsg{Width: 80, Height: 30})
+	assert.Equal(t, 80, m.width)
+	assert.Equal(t, 30-2-1-tableChrome, m.table.PageSize())
+
+	m.Update(tea.WindowSizeMsg{Width: 80, Height: 5})
+	assert.Equal(t, 1, m.table.PageSize())
+}
+
+func TestColumnMoveKeepsRowCursor(t *testing.T) {
+	data := make([]map[string]string, 10)
+	for i := range data {
+		data[i] = map[string]string{"id": fmt.Sprint(i), "name": fmt.Sprint("n", i)}
+	}
+	m := NewModel(data, []string{"id", "name"}, nil, QueryContext{}, DefaultKeyMap())
+	m.Update(tea.WindowSizeMsg{Width: 80, Height: 30})
+
+	for range 5 {
+		m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	assert.Equal(t, 1, m.colCursor)
+	assert.Equal(t, 5, m.table.GetHighlightedRowIndex())
+}
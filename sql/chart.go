@@ -0,0 +1,298 @@
+package sql
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const chartLabelWidth = 24
+const chartMaxBins = 20
+
+// chartAxisWidth is kept free for the value labels left of a line chart.
+const chartAxisWidth = 10
+
+var horizontalEighths = []string{"", "▏", "▎", "▍", "▌", "▋", "▊", "▉"}
+var verticalEighths = []string{" ", "▁", "▂", "▃", "▄", "▅", "▆", "▇"}
+
+type chartKind int
+
+const (
+	barChart chartKind = iota
+	lineChart
+	histogramChart
+)
+
+// chartView plots a column of the filtered rows. Categories get a bar chart
+// and times a line chart of a numeric measure, numbers a histogram.
+type chartView struct {
+	kind     chartKind
+	column   string
+	measure  string   // the numeric column plotted, or "" to count rows
+	measures []string // numeric columns the measure cycles through
+	rows     []Record
+}
+
+func newChartView(rows []Record, column string, cols []string, kinds map[string]valueKind) chartView {
+	v := chartView{kind: barChart, column: column, rows: rows}
+	switch kinds[column] {
+	case kindNumber:
+		v.kind = histogramChart
+	case kindTime:
+		v.kind = lineChart
+	}
+	for _, c := range cols {
+		if c != column && kinds[c] == kindNumber {
+			v.measures = append(v.measures, c)
+		}
+	}
+	if v.kind == lineChart && len(v.measures) > 0 {
+		v.measure = v.measures[0]
+	}
+	return v
+}
+
+// nextMeasure cycles through the numeric columns and the row count.
+func (v *chartView) nextMeasure() {
+	if v.kind == histogramChart {
+		return
+	}
+	options := append([]string{""}, v.measures...)
+	v.measure = options[(slices.Index(options, v.measure)+1)%len(options)]
+}
+
+func (v chartView) title() string {
+	switch v.kind {
+	case histogramChart:
+		return "Histogram of " + v.column
+	case lineChart:
+		if v.measure == "" {
+			return "Rows over " + v.column
+		}
+		return fmt.Sprintf("avg(%s) over %s", v.measure, v.column)
+	}
+	if v.measure == "" {
+		return "Rows by " + v.column
+	}
+	return fmt.Sprintf("sum(%s) by %s", v.measure, v.column)
+}
+
+func (v chartView) View(width int, height int, keys navigationKeyMap) string {
+	var body string
+	switch v.kind {
+	case histogramChart:
+		body = v.histogram(width, height-4)
+	case lineChart:
+		body = v.line(width, height-4)
+	default:
+		body = v.bars(width, height-4)
+	}
+	footer := keyHint(keys.Back, "back")
+	if v.kind != histogramChart {
+		footer = keyHint(keys.NextMeasure, "change measure") + " · " + footer
+	}
+	return headerStyle.Render(v.title()) + "\n\n" + body + "\n" + statusStyle.Render(footer)
+}
+
+// measureOf is what a row contributes to the chart, if anything.
+func (v chartView) measureOf(r Record) (float64, bool) {
+	if v.measure == "" {
+		return 1, true
+	}
+	return parseNumber(r[v.measure])
+}
+
+type chartBar struct {
+	label string
+	value float64
+}
+
+func (v chartView) bars(width int, height int) string {
+	var bars []chartBar
+	index := map[string]int{}
+	for _, r := range v.rows {
+		n, ok := v.measureOf(r)
+		if !ok {
+			continue
+		}
+		i, seen := index[r[v.column]]
+		if !seen {
+			i = len(bars)
+			index[r[v.column]] = i
+			bars = append(bars, chartBar{label: r[v.column]})
+		}
+		bars[i].value += n
+	}
+	slices.SortStableFunc(bars, func(a, b chartBar) int {
+		return cmp.Compare(b.value, a.value)
+	})
+	if len(bars) > height {
+		hidden := len(bars) - max(1, height-1)
+		bars = bars[:len(bars)-hidden]
+		return renderBars(bars, width) + statusStyle.Render(fmt.Sprintf("… and %d more\n", hidden))
+	}
+	return renderBars(bars, width)
+}
+
+func (v chartView) histogram(width int, height int) string {
+	var values []float64
+	for _, r := range v.rows {
+		if n, ok := parseNumber(r[v.column]); ok {
+			values = append(values, n)
+		}
+	}
+	slices.Sort(values)
+	var bars []chartBar
+	for _, bin := range histogram(values, max(1, min(height, chartMaxBins))) {
+		label := fmt.Sprintf("[%s, %s)", formatNumber(bin.low), formatNumber(bin.high))
+		bars = append(bars, chartBar{label, float64(bin.count)})
+	}
+	return renderBars(bars, width)
+}
+
+// renderBars draws one labelled horizontal bar per line, scaled to the largest value.
+func renderBars(bars []chartBar, width int) string {
+	if len(bars) == 0 {
+		return "No values to plot.\n"
+	}
+	labelWidth, valueWidth, largest := 0, 0, 0.0
+	for _, b := range bars {
+		labelWidth = max(labelWidth, min(lipgloss.Width(b.label), chartLabelWidth))
+		valueWidth = max(valueWidth, len(formatAggregate(b.value)))
+		largest = max(largest, b.value)
+	}
+	barWidth := max(10, width-labelWidth-valueWidth-2)
+	var s strings.Builder
+	for _, b := range bars {
+		fraction := 0.0
+		if largest > 0 {
+			fraction = max(0, b.value/largest)
+		}
+		fmt.Fprintf(&s, "%-*s %s %s\n", labelWidth, truncateLabel(b.label, chartLabelWidth), chartStyle.Render(horizontalBar(fraction, barWidth)), formatAggregate(b.value))
+	}
+	return s.String()
+}
+
+// horizontalBar fills fraction of width, in eighths of a character.
+func horizontalBar(fraction float64, width int) string {
+	eighths := int(math.Round(fraction * float64(width) * 8))
+	if fraction > 0 && eighths == 0 {
+		eighths = 1
+	}
+	return strings.Repeat("█", eighths/8) + horizontalEighths[eighths%8]
+}
+
+func truncateLabel(label string, width int) string {
+	runes := []rune(label)
+	if len(runes) <= width {
+		return label
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+type chartPoint struct {
+	at    time.Time
+	value float64
+}
+
+// line plots the measure over time, one bucket of time per character.
+func (v chartView) line(width int, height int) string {
+	var points []chartPoint
+	for _, r := range v.rows {
+		at, ok := parseTime(r[v.column])
+		if !ok {
+			continue
+		}
+		if n, ok := v.measureOf(r); ok {
+			points = append(points, chartPoint{at, n})
+		}
+	}
+	if len(points) == 0 {
+		return "No values to plot.\n"
+	}
+	slices.SortFunc(points, func(a, b chartPoint) int { return a.at.Compare(b.at) })
+	first, last := points[0].at, points[len(points)-1].at
+
+	// rows are counted per bucket, measures averaged
+	plotWidth := max(10, width-chartAxisWidth-2)
+	sums := make([]float64, plotWidth)
+	counts := make([]int, plotWidth)
+	span := last.Sub(first)
+	for _, p := range points {
+		i := 0
+		if span > 0 {
+			i = int(float64(p.at.Sub(first)) / float64(span) * float64(plotWidth-1))
+		}
+		sums[i] += p.value
+		counts[i]++
+	}
+	buckets := make([]float64, plotWidth)
+	low, high := math.Inf(1), math.Inf(-1)
+	for i := range buckets {
+		if counts[i] == 0 {
+			continue
+		}
+		buckets[i] = sums[i]
+		if v.measure != "" {
+			buckets[i] /= float64(counts[i])
+		}
+		low, high = min(low, buckets[i]), max(high, buckets[i])
+	}
+	if low == high {
+		low = min(0, low)
+		if low == high {
+			high = low + 1
+		}
+	}
+
+	plotHeight := max(3, height-2)
+	highLabel, lowLabel := formatNumber(high), formatNumber(low)
+	labelWidth := max(chartAxisWidth, len(highLabel), len(lowLabel))
+	var s strings.Builder
+	for row := range plotHeight {
+		label := ""
+		if row == 0 {
+			label = highLabel
+		} else if row == plotHeight-1 {
+			label = lowLabel
+		}
+		var line strings.Builder
+		for i, value := range buckets {
+			if counts[i] == 0 {
+				line.WriteString(" ")
+				continue
+			}
+			// the lowest value still gets a sliver so that it is visible
+			level := max(1, int(math.Round((value-low)/(high-low)*float64(plotHeight*8))))
+			fill := level - (plotHeight-1-row)*8
+			switch {
+			case fill >= 8:
+				line.WriteString("█")
+			case fill <= 0:
+				line.WriteString(" ")
+			default:
+				line.WriteString(verticalEighths[fill])
+			}
+		}
+		fmt.Fprintf(&s, "%*s │%s\n", labelWidth, label, chartStyle.Render(line.String()))
+	}
+	fmt.Fprintf(&s, "%*s └%s\n", labelWidth, "", strings.Repeat("─", plotWidth))
+	start, end := formatChartTime(first, last), formatChartTime(last, first)
+	gap := max(1, plotWidth-len(start)-len(end))
+	fmt.Fprintf(&s, "%*s  %s%s%s\n", labelWidth, "", start, strings.Repeat(" ", gap), end)
+	return s.String()
+}
+
+// formatChartTime leaves out the time of day when neither end of the axis has one.
+func formatChartTime(t time.Time, other time.Time) string {
+	midnight := func(t time.Time) bool { return t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 }
+	if midnight(t) && midnight(other) {
+		return t.Format("2006-01-02")
+	}
+	return t.Format("2006-01-02 15:04")
+}
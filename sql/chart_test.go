@@ -0,0 +1,65 @@
+package sql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/stretchr/testify/assert"
+)
+
+var chartRows = []Record{
+	{"city": "Utrecht", "amount": "10", "day": "2024-01-01"},
+	{"city": "Leiden", "amount": "4", "day": "2024-01-02"},
+	{"city": "Utrecht", "amount": "6", "day": "2024-01-03"},
+	{"city": "Delft", "amount": NullValue, "day": "2024-01-05"},
+}
+
+var chartKinds = map[string]valueKind{"city": kindString, "amount": kindNumber, "day": kindTime}
+
+func TestChartKindFollowsColumn(t *testing.T) {
+	cols := []string{"city", "amount", "day"}
+	assert.Equal(t, barChart, newChartView(chartRows, "city", cols, chartKinds).kind)
+	assert.Equal(t, histogramChart, newChartView(chartRows, "amount", cols, chartKinds).kind)
+
+	line := newChartView(chartRows, "day", cols, chartKinds)
+	assert.Equal(t, lineChart, line.kind)
+	assert.Equal(t, "amount", line.measure)
+	line.nextMeasure()
+	assert.Equal(t, "", line.measure)
+}
+
+func TestBarChart(t *testing.T) {
+	v := newChartView(chartRows, "city", []string{"city", "amount"}, chartKinds)
+	lines := strings.Split(strings.TrimSpace(v.bars(40, 10)), "\n")
+	assert.Len(t, lines, 3)
+	assert.True(t, strings.HasPrefix(lines[0], "Utrecht"))
+	assert.True(t, strings.HasSuffix(lines[0], " 2"))
+
+	v.nextMeasure()
+	lines = strings.Split(strings.TrimSpace(v.bars(40, 10)), "\n")
+	// the NULL amount of Delft is left out
+	assert.Len(t, lines, 2)
+	assert.True(t, strings.HasSuffix(lines[0], " 16"))
+	assert.Equal(t, 40, lipgloss.Width(lines[0]))
+
+	lines = strings.Split(strings.TrimSpace(v.bars(40, 1)), "\n")
+	assert.Equal(t, "… and 1 more", lines[len(lines)-1])
+}
+
+func TestHorizontalBar(t *testing.T) {
+	assert.Equal(t, "", horizontalBar(0, 10))
+	assert.Equal(t, "▏", horizontalBar(0.001, 10))
+	assert.Equal(t, "█████", horizontalBar(0.5, 10))
+	assert.Equal(t, "█████▌", horizontalBar(0.55, 10))
+}
+
+func TestLineChartFitsWidth(t *testing.T) {
+	v := newChartView(chartRows, "day", []string{"amount"}, chartKinds)
+	out := v.line(60, 10)
+	for _, line := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+		assert.LessOrEqual(t, lipgloss.Width(line), 60)
+	}
+	assert.Contains(t, out, "2024-01-01")
+	assert.Contains(t, out, "2024-01-03")
+}
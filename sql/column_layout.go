@@ -0,0 +1,140 @@
+package sql
+
+import (
+	"slices"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// ColumnLayout is the column order and pinning chosen for a query, so that it
+// can be restored the next time the query is viewed.
+type ColumnLayout struct {
+	Order  []string
+	Pinned []string
+}
+
+// orderColumns arranges cols by order. Columns that order does not mention,
+// e.g. ones added to the query since, keep their place at the end.
+func orderColumns(cols []string, order []string) []string {
+	out := make([]string, 0, len(cols))
+	for _, c := range order {
+		if slices.Contains(cols, c) && !slices.Contains(out, c) {
+			out = append(out, c)
+		}
+	}
+	for _, c := range cols {
+		if !slices.Contains(out, c) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// arrangeColumns shows the selected columns in the order of allCols, with the
+// pinned ones first so that they stay put while scrolling.
+func (m *model) arrangeColumns(selected []string) {
+	var pinned, rest []string
+	for _, c := range m.allCols {
+		if !slices.Contains(selected, c) {
+			continue
+		}
+		if m.pinned[c] {
+			pinned = append(pinned, c)
+		} else {
+			rest = append(rest, c)
+		}
+	}
+	m.visibleCols = append(pinned, rest...)
+	m.pinnedCount = len(pinned)
+}
+
+// togglePin pins or unpins the current column.
+func (m *model) togglePin() {
+	c := m.currentColumn()
+	if c == "" {
+		return
+	}
+	if m.pinned[c] {
+		delete(m.pinned, c)
+	} else {
+		m.pinned[c] = true
+	}
+	m.arrangeColumns(m.visibleCols)
+	m.colCursor = slices.Index(m.visibleCols, c)
+	m.buildTable(m.filteredRows)
+	m.saveLayout()
+}
+
+// moveColumn swaps the current column with its neighbour. Pinned columns only
+// move among pinned columns and the rest among themselves.
+func (m *model) moveColumn(delta int) {
+	i, j := m.colCursor, m.colCursor+delta
+	if len(m.visibleCols) == 0 || j < 0 || j >= len(m.visibleCols) {
+		return
+	}
+	a, b := m.visibleCols[i], m.visibleCols[j]
+	if m.pinned[a] != m.pinned[b] {
+		return
+	}
+	ai, bi := slices.Index(m.allCols, a), slices.Index(m.allCols, b)
+	m.allCols[ai], m.allCols[bi] = b, a
+	m.arrangeColumns(m.visibleCols)
+	m.colCursor = j
+	m.syncColumnList()
+	m.buildTable(m.filteredRows)
+	m.saveLayout()
+}
+
+// syncColumnList puts the visible column picker back in the order of allCols.
+func (m *model) syncColumnList() {
+	selected := map[string]bool{}
+	for _, it := range m.listVisible.Items() {
+		ci := it.(columnItem)
+		selected[ci.name] = ci.selected
+	}
+	items := make([]list.Item, len(m.allCols))
+	for i, c := range m.allCols {
+		items[i] = columnItem{c, selected[c]}
+	}
+	m.listVisible.SetItems(items)
+}
+
+func (m *model) saveLayout() {
+	if m.queryContext.SaveLayout == nil {
+		return
+	}
+	layout := ColumnLayout{Order: slices.Clone(m.allCols)}
+	for _, c := range m.allCols {
+		if m.pinned[c] {
+			layout.Pinned = append(layout.Pinned, c)
+		}
+	}
+	m.queryContext.SaveLayout(layout)
+}
+
+// moveListItem moves the selected item of a column picker up or down. A
+// filtered picker only shows some of the columns, so moving is left until the
+// filter is cleared.
+func moveListItem(l *list.Model, delta int) {
+	if l.FilterState() != list.Unfiltered {
+		return
+	}
+	i, j := l.Index(), l.Index()+delta
+	items := l.Items()
+	if j < 0 || j >= len(items) {
+		return
+	}
+	a, b := items[i], items[j]
+	l.SetItem(i, b)
+	l.SetItem(j, a)
+	l.Select(j)
+}
+
+// listOrder is the name of every column in a picker, in its current order.
+func listOrder(l *list.Model) []string {
+	names := make([]string, len(l.Items()))
+	for i, it := range l.Items() {
+		names[i] = it.(columnItem).name
+	}
+	return names
+}
@@ -0,0 +1,53 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderColumns(t *testing.T) {
+	cols := []string{"id", "name", "city", "added"}
+	assert.Equal(t, []string{"city", "id", "name", "added"}, orderColumns(cols, []string{"city", "removed", "id", "name"}))
+	assert.Equal(t, cols, orderColumns(cols, nil))
+}
+
+func TestPinAndMoveColumns(t *testing.T) {
+	var saved ColumnLayout
+	data := []map[string]string{{"id": "1", "name": "a", "city": "x"}}
+	m := NewModel(data, []string{"id", "name", "city"}, nil, QueryContext{
+		Layout:     ColumnLayout{Pinned: []string{"city"}},
+		SaveLayout: func(layout ColumnLayout) { saved = layout },
+	}, DefaultKeyMap())
+	assert.Equal(t, []string{"city", "id", "name"}, m.visibleCols)
+	assert.Equal(t, 1, m.pinnedCount)
+
+	// pinned and unpinned columns stay in their own groups
+	m.moveColumn(1)
+	assert.Equal(t, []string{"city", "id", "name"}, m.visibleCols)
+
+	m.colCursor = 1
+	m.moveColumn(1)
+	assert.Equal(t, []string{"city", "name", "id"}, m.visibleCols)
+	assert.Equal(t, 2, m.colCursor)
+	assert.Equal(t, []string{"name", "id", "city"}, listOrder(&m.listVisible))
+
+	m.togglePin()
+	assert.Equal(t, []string{"id", "city", "name"}, m.visibleCols)
+	assert.Equal(t, 2, m.pinnedCount)
+	assert.Equal(t, ColumnLayout{Order: []string{"name", "id", "city"}, Pinned: []string{"id", "city"}}, saved)
+}
+
+func TestFilteredColumnListDoesNotMove(t *testing.T) {
+	data := []map[string]string{{"id": "1", "name": "a", "city": "x"}}
+	m := NewModel(data, []string{"id", "name", "city"}, nil, QueryContext{}, DefaultKeyMap())
+
+	m.listVisible.SetFilterText("city")
+	moveListItem(&m.listVisible, -1)
+	m.listVisible.ResetFilter()
+	assert.Equal(t, []string{"id", "name", "city"}, listOrder(&m.listVisible))
+
+	m.listVisible.Select(2)
+	moveListItem(&m.listVisible, -1)
+	assert.Equal(t, []string{"id", "city", "name"}, listOrder(&m.listVisible))
+}
@@ -0,0 +1,69 @@
+package sql
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const minColumnWidth = 4
+const maxAutoColumnWidth = 50
+const widthSampleRows = 200
+const widthStep = 2
+
+// headerAllowance leaves room in the title for the cursor marker and a sort indicator.
+const headerAllowance = 4
+
+// autoColumnWidth sizes a column to its header and a sample of its values.
+func autoColumnWidth(rows []Record, col string) int {
+	width := lipgloss.Width(col) + headerAllowance
+	for i := 0; i < len(rows) && i < widthSampleRows; i++ {
+		value, _, _ := strings.Cut(rows[i][col], "\n")
+		width = max(width, lipgloss.Width(value))
+	}
+	return max(minColumnWidth, min(width, maxAutoColumnWidth))
+}
+
+// fitWidths shrinks widths proportionally so that they sum to at most
+// available, never going below minColumnWidth.
+func fitWidths(widths []int, available int) []int {
+	total := 0
+	for _, w := range widths {
+		total += w
+	}
+	if total <= available || total == 0 {
+		return widths
+	}
+	fitted := make([]int, len(widths))
+	for i, w := range widths {
+		fitted[i] = max(minColumnWidth, w*available/total)
+	}
+	return fitted
+}
+
+// columnWidths returns the width of each visible column, applying the user's
+// adjustments and keeping every column narrower than the terminal.
+func (m *model) columnWidths() []int {
+	widths := make([]int, len(m.visibleCols))
+	limit := max(minColumnWidth, m.width-4)
+	for i, c := range m.visibleCols {
+		widths[i] = max(minColumnWidth, min(m.autoWidths[c]+m.widthAdjust[c], limit))
+	}
+	if m.fitAll {
+		// each column also draws one border character
+		widths = fitWidths(widths, m.width-len(widths)-1)
+	}
+	return widths
+}
+
+// adjustColumnWidth widens or narrows the current column by delta characters.
+func (m *model) adjustColumnWidth(delta int) {
+	c := m.currentColumn()
+	limit := max(minColumnWidth, m.width-4)
+	width := m.autoWidths[c] + m.widthAdjust[c] + delta
+	if width < minColumnWidth || width > limit {
+		return
+	}
+	m.widthAdjust[c] += delta
+	m.buildTable(m.filteredRows)
+}
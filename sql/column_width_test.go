@@ -0,0 +1,46 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutoColumnWidth(t *testing.T) {
+	rows := []Record{
+		{"id": "1", "name": "a fairly long customer name", "note": "x\nsecond line is ignored"},
+		{"id": "22", "name": "short", "note": "y"},
+	}
+
+	assert.Equal(t, len("id")+headerAllowance, autoColumnWidth(rows, "id"))
+	assert.Equal(t, len("a fairly long customer name"), autoColumnWidth(rows, "name"))
+	assert.Equal(t, len("note")+headerAllowance, autoColumnWidth(rows, "note"))
+
+	long := []Record{{"c": strings.Repeat("x", 500)}}
+	assert.Equal(t, maxAutoColumnWidth, autoColumnWidth(long, "c"))
+}
+
+func TestFitWidths(t *testing.T) {
+	assert.Equal(t, []int{10, 20}, fitWidths([]int{10, 20}, 40))
+	assert.Equal(t, []int{10, 20}, fitWidths([]int{20, 40}, 30))
+	assert.Equal(t, []int{minColumnWidth, 24}, fitWidths([]int{2, 48}, 25))
+}
+
+func TestWidthChangeKeepsRowCursor(t *testing.T) {
+	data := make([]map[string]string, 10)
+	for i := range data {
+		data[i] = map[string]string{"id": fmt.Sprint(i)}
+	}
+	m := NewModel(data, []string{"id"}, nil, QueryContext{}, DefaultKeyMap())
+	m.Update(tea.WindowSizeMsg{Width: 80, Height: 30})
+	for range 4 {
+		m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+
+	m.adjustColumnWidth(widthStep)
+	assert.Equal(t, widthStep, m.widthAdjust["id"])
+	assert.Equal(t, 4, m.table.GetHighlightedRowIndex())
+}
@@ -5,25 +5,60 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"sync"
 
 	dbsql "github.com/databricks/databricks-sql-go"
+	"github.com/databricks/databricks-sql-go/auth"
 )
 
+// NullValue is how a NULL column value is represented in query results.
+const NullValue = "<nil>"
+
 type Connection interface {
 	Query(sqlString string) (*sql.Rows, error)
-	RunQueryFromFile(filePath string) ([]map[string]string, []string, error)
+	RunQuery(sqlString string) ([]map[string]string, []string, error)
+	RunQueryFromFile(filePath string) ([]map[string]string, []string, map[string]string, error)
 }
 
 type DatabricksConnection struct {
 	AccessToken    string
 	ServerHostname string
 	HttpPath       string
-	Logger         *slog.Logger
+	// AuthType is AuthPAT to use AccessToken, or one of the OAuth flows
+	AuthType     string
+	ClientID     string
+	ClientSecret string
+	// TokenCacheDir keeps OAuth tokens between runs
+	TokenCacheDir string
+	Logger        *slog.Logger
+
+	// the authenticator is shared by the queries of the connection, so that
+	// they reuse one token rather than each logging in
+	authOnce sync.Once
+	auth     auth.Authenticator
+	authErr  error
+}
+
+// authenticator authorizes the requests of c for its auth type, or is nil for
+// an access token.
+func (c *DatabricksConnection) authenticator() (auth.Authenticator, error) {
+	c.authOnce.Do(func() {
+		c.auth, c.authErr = c.newAuthenticator()
+	})
+	return c.auth, c.authErr
 }
 
-func (c DatabricksConnection) Query(sqlString string) (*sql.Rows, error) {
+func (c *DatabricksConnection) Query(sqlString string) (*sql.Rows, error) {
+	authenticator, err := c.authenticator()
+	if err != nil {
+		return nil, err
+	}
+	credentials := dbsql.WithAccessToken(c.AccessToken)
+	if authenticator != nil {
+		credentials = dbsql.WithAuthenticator(authenticator)
+	}
 	connector, err := dbsql.NewConnector(
-		dbsql.WithAccessToken(c.AccessToken),
+		credentials,
 		dbsql.WithServerHostname(c.ServerHostname),
 		dbsql.WithPort(443),
 		dbsql.WithHTTPPath(c.HttpPath),
@@ -39,20 +74,46 @@ func (c DatabricksConnection) Query(sqlString string) (*sql.Rows, error) {
 	return rows, err
 }
 
-func (c DatabricksConnection) RunQueryFromFile(filePath string) ([]map[string]string, []string, error) {
+// RunQueryFromFile runs the query stored in filePath, also returning the
+// database type name of each column.
+func (c *DatabricksConnection) RunQueryFromFile(filePath string) ([]map[string]string, []string, map[string]string, error) {
 	data, err := os.ReadFile(filePath)
 
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	rows, err := c.Query(string(data))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer rows.Close()
+	return scanRows(rows)
+}
+
+// RunQuery executes sqlString and collects every row as a map of column name to value.
+func (c *DatabricksConnection) RunQuery(sqlString string) ([]map[string]string, []string, error) {
+	rows, err := c.Query(sqlString)
 	if err != nil {
 		return nil, nil, err
 	}
+	defer rows.Close()
+	maps, cols, _, err := scanRows(rows)
+	return maps, cols, err
+}
 
+func scanRows(rows *sql.Rows) ([]map[string]string, []string, map[string]string, error) {
 	cols, err := rows.Columns()
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
+	}
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	types := map[string]string{}
+	for i, columnType := range columnTypes {
+		types[cols[i]] = columnType.DatabaseTypeName()
 	}
 
 	// Map of column names to value.
@@ -67,17 +128,21 @@ func (c DatabricksConnection) RunQueryFromFile(filePath string) ([]map[string]st
 
 		err := rows.Scan(vals...)
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, nil, err
 		}
 
 		m := map[string]string{}
 		for i, colName := range cols {
 			raw := vals[i].(*any)
-			m[colName] = fmt.Sprintf("%v", *raw)
+			if *raw == nil {
+				m[colName] = NullValue
+			} else {
+				m[colName] = fmt.Sprintf("%v", *raw)
+			}
 		}
 
 		maps = append(maps, m)
 	}
 
-	return maps, cols, nil
+	return maps, cols, types, nil
 }
@@ -0,0 +1,53 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// planningErrorPrefix is how Databricks reports a query that fails analysis
+// inside the EXPLAIN output rather than as a query error.
+const planningErrorPrefix = "Error occurred during query planning:"
+
+// trimStatement removes surrounding whitespace and a trailing semicolon so the
+// query can be embedded in another statement.
+func trimStatement(query string) string {
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(query), ";"))
+}
+
+// DryRun asks the warehouse to plan query without running it.
+func DryRun(connection Connection, query string) error {
+	_, err := Explain(connection, query, ExplainSimple)
+	return err
+}
+
+type ExplainMode string
+
+const (
+	ExplainSimple    ExplainMode = ""
+	ExplainFormatted ExplainMode = "FORMATTED"
+	ExplainExtended  ExplainMode = "EXTENDED"
+	ExplainCost      ExplainMode = "COST"
+)
+
+// Explain returns the plan text the warehouse produces for query.
+func Explain(connection Connection, query string, mode ExplainMode) (string, error) {
+	statement := "EXPLAIN "
+	if mode != ExplainSimple {
+		statement += string(mode) + " "
+	}
+	rows, cols, err := connection.RunQuery(statement + trimStatement(query))
+	if err != nil {
+		return "", err
+	}
+	var lines []string
+	for _, row := range rows {
+		for _, col := range cols {
+			if _, message, found := strings.Cut(row[col], planningErrorPrefix); found {
+				return "", fmt.Errorf("%s", strings.TrimSpace(message))
+			}
+			lines = append(lines, row[col])
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
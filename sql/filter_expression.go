@@ -0,0 +1,380 @@
+package sql
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// A filter expression combines per column conditions, for example
+//
+//	amount > 100 and country = 'UK'
+//	name ~ /^a/i or not (status in ('done', 'failed'))
+//	closed_at is not null
+//
+// Comparisons use the column's type, so numbers and dates compare by value.
+// Any comparison against a NULL is false; use `is null` to find them.
+type filterExpression interface {
+	match(r Record, kinds map[string]valueKind) bool
+}
+
+type filterError struct {
+	position int
+	message  string
+}
+
+func (e filterError) Error() string {
+	return fmt.Sprintf("%s (at %d)", e.message, e.position+1)
+}
+
+type andExpression struct{ left, right filterExpression }
+type orExpression struct{ left, right filterExpression }
+type notExpression struct{ inner filterExpression }
+
+type comparisonExpression struct {
+	column   string
+	operator string
+	values   []string
+}
+
+type nullExpression struct {
+	column string
+	negate bool
+}
+
+type regexExpression struct {
+	column string
+	re     *regexp.Regexp
+	negate bool
+}
+
+func (e andExpression) match(r Record, kinds map[string]valueKind) bool {
+	return e.left.match(r, kinds) && e.right.match(r, kinds)
+}
+
+func (e orExpression) match(r Record, kinds map[string]valueKind) bool {
+	return e.left.match(r, kinds) || e.right.match(r, kinds)
+}
+
+func (e notExpression) match(r Record, kinds map[string]valueKind) bool {
+	return !e.inner.match(r, kinds)
+}
+
+func (e nullExpression) match(r Record, kinds map[string]valueKind) bool {
+	return isNull(r[e.column]) != e.negate
+}
+
+func (e regexExpression) match(r Record, kinds map[string]valueKind) bool {
+	value := r[e.column]
+	if isNull(value) {
+		return false
+	}
+	return e.re.MatchString(value) != e.negate
+}
+
+func (e comparisonExpression) match(r Record, kinds map[string]valueKind) bool {
+	value := r[e.column]
+	if isNull(value) {
+		return false
+	}
+	kind := kinds[e.column]
+	switch e.operator {
+	case "in":
+		return slices.ContainsFunc(e.values, func(v string) bool { return compareValues(value, v, kind) == 0 })
+	case "not in":
+		return !slices.ContainsFunc(e.values, func(v string) bool { return compareValues(value, v, kind) == 0 })
+	}
+	result := compareValues(value, e.values[0], kind)
+	switch e.operator {
+	case "=":
+		return result == 0
+	case "!=", "<>":
+		return result != 0
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	}
+	return false
+}
+
+// ─── Lexer ────────────────────────────────────────────────────────────────────
+
+type filterTokenKind int
+
+const (
+	filterWord filterTokenKind = iota
+	filterString
+	filterRegex
+	filterOperator
+	filterOpen
+	filterClose
+	filterComma
+	filterEnd
+)
+
+type filterToken struct {
+	kind     filterTokenKind
+	text     string
+	position int
+}
+
+func lexFilter(input string) ([]filterToken, error) {
+	runes := []rune(input)
+	var tokens []filterToken
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case r == '(':
+			tokens = append(tokens, filterToken{filterOpen, "(", start})
+			i++
+		case r == ')':
+			tokens = append(tokens, filterToken{filterClose, ")", start})
+			i++
+		case r == ',':
+			tokens = append(tokens, filterToken{filterComma, ",", start})
+			i++
+		case r == '\'' || r == '"' || r == '`' || r == '/':
+			var b strings.Builder
+			i++
+			for i < len(runes) && runes[i] != r {
+				if runes[i] == '\\' && i+1 < len(runes) && (runes[i+1] == r || r != '/') {
+					i++
+				}
+				b.WriteRune(runes[i])
+				i++
+			}
+			if i >= len(runes) {
+				return nil, filterError{start, fmt.Sprintf("unterminated %c", r)}
+			}
+			i++
+			switch r {
+			case '`':
+				tokens = append(tokens, filterToken{filterWord, b.String(), start})
+			case '/':
+				pattern := b.String()
+				if i < len(runes) && runes[i] == 'i' {
+					pattern = "(?i)" + pattern
+					i++
+				}
+				tokens = append(tokens, filterToken{filterRegex, pattern, start})
+			default:
+				tokens = append(tokens, filterToken{filterString, b.String(), start})
+			}
+		case strings.ContainsRune("=!<>~", r):
+			for i < len(runes) && strings.ContainsRune("=!<>~", runes[i]) {
+				i++
+			}
+			op := string(runes[start:i])
+			if !slices.Contains([]string{"=", "==", "!=", "<>", "<", "<=", ">", ">=", "~", "!~"}, op) {
+				return nil, filterError{start, "unknown operator " + op}
+			}
+			if op == "==" {
+				op = "="
+			}
+			tokens = append(tokens, filterToken{filterOperator, op, start})
+		default:
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune("()',\"=!<>~", runes[i]) {
+				i++
+			}
+			tokens = append(tokens, filterToken{filterWord, string(runes[start:i]), start})
+		}
+	}
+	return append(tokens, filterToken{filterEnd, "", len(runes)}), nil
+}
+
+// ─── Parser ───────────────────────────────────────────────────────────────────
+
+type filterParser struct {
+	tokens  []filterToken
+	pos     int
+	columns []string
+}
+
+// parseFilterExpression compiles input against the available columns.
+func parseFilterExpression(input string, columns []string) (filterExpression, error) {
+	tokens, err := lexFilter(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{tokens: tokens, columns: columns}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != filterEnd {
+		return nil, filterError{tok.position, "unexpected " + tok.text}
+	}
+	return expr, nil
+}
+
+func (p *filterParser) peek() filterToken {
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) next() filterToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != filterEnd {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *filterParser) isKeyword(word string) bool {
+	tok := p.peek()
+	return tok.kind == filterWord && strings.EqualFold(tok.text, word)
+}
+
+func (p *filterParser) parseOr() (filterExpression, error) {
+	left, err := p.parseAnd()
+	for err == nil && p.isKeyword("or") {
+		p.next()
+		var right filterExpression
+		right, err = p.parseAnd()
+		left = orExpression{left, right}
+	}
+	return left, err
+}
+
+func (p *filterParser) parseAnd() (filterExpression, error) {
+	left, err := p.parseNot()
+	for err == nil && p.isKeyword("and") {
+		p.next()
+		var right filterExpression
+		right, err = p.parseNot()
+		left = andExpression{left, right}
+	}
+	return left, err
+}
+
+func (p *filterParser) parseNot() (filterExpression, error) {
+	if p.isKeyword("not") {
+		p.next()
+		inner, err := p.parseNot()
+		return notExpression{inner}, err
+	}
+	if p.peek().kind == filterOpen {
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if tok := p.next(); tok.kind != filterClose {
+			return nil, filterError{tok.position, "expected )"}
+		}
+		return inner, nil
+	}
+	return p.parseCondition()
+}
+
+func (p *filterParser) parseColumn() (string, error) {
+	tok := p.next()
+	if tok.kind != filterWord && tok.kind != filterString {
+		return "", filterError{tok.position, "expected a column name"}
+	}
+	for _, c := range p.columns {
+		if c == tok.text {
+			return c, nil
+		}
+	}
+	for _, c := range p.columns {
+		if strings.EqualFold(c, tok.text) {
+			return c, nil
+		}
+	}
+	return "", filterError{tok.position, "unknown column " + tok.text}
+}
+
+func (p *filterParser) parseValue() (string, error) {
+	tok := p.next()
+	if tok.kind != filterWord && tok.kind != filterString {
+		return "", filterError{tok.position, "expected a value"}
+	}
+	if tok.kind == filterWord && strings.EqualFold(tok.text, "null") {
+		return "", filterError{tok.position, "use 'is null' to compare with null"}
+	}
+	return tok.text, nil
+}
+
+func (p *filterParser) parseCondition() (filterExpression, error) {
+	column, err := p.parseColumn()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case p.isKeyword("is"):
+		p.next()
+		negate := false
+		if p.isKeyword("not") {
+			p.next()
+			negate = true
+		}
+		if !p.isKeyword("null") {
+			return nil, filterError{p.peek().position, "expected null"}
+		}
+		p.next()
+		return nullExpression{column, negate}, nil
+
+	case p.isKeyword("in"), p.isKeyword("not"):
+		operator := "in"
+		if p.isKeyword("not") {
+			p.next()
+			if !p.isKeyword("in") {
+				return nil, filterError{p.peek().position, "expected in"}
+			}
+			operator = "not in"
+		}
+		p.next()
+		if tok := p.next(); tok.kind != filterOpen {
+			return nil, filterError{tok.position, "expected ("}
+		}
+		var values []string
+		for {
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+			tok := p.next()
+			if tok.kind == filterClose {
+				break
+			}
+			if tok.kind != filterComma {
+				return nil, filterError{tok.position, "expected , or )"}
+			}
+		}
+		return comparisonExpression{column, operator, values}, nil
+	}
+
+	tok := p.next()
+	if tok.kind != filterOperator {
+		return nil, filterError{tok.position, "expected an operator after " + column}
+	}
+	if tok.text == "~" || tok.text == "!~" {
+		pattern := p.next()
+		if pattern.kind != filterRegex && pattern.kind != filterString {
+			return nil, filterError{pattern.position, "expected /regex/"}
+		}
+		re, err := regexp.Compile(pattern.text)
+		if err != nil {
+			return nil, filterError{pattern.position, "invalid regex: " + err.Error()}
+		}
+		return regexExpression{column, re, tok.text == "!~"}, nil
+	}
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	return comparisonExpression{column, tok.text, []string{value}}, nil
+}
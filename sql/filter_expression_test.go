@@ -0,0 +1,68 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var filterRows = []Record{
+	{"name": "Alice", "amount": "150", "country": "UK", "closed": NullValue},
+	{"name": "bob", "amount": "90", "country": "UK", "closed": "2024-01-02"},
+	{"name": "Anna", "amount": "1000", "country": "FR", "closed": NullValue},
+}
+
+var filterKinds = map[string]valueKind{"name": kindString, "amount": kindNumber, "country": kindString, "closed": kindTime}
+
+func TestFilterExpressionMatches(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected []string
+	}{
+		{"amount > 100 and country = 'UK'", []string{"Alice"}},
+		{"amount >= 90 and amount < 1000", []string{"Alice", "bob"}},
+		{"name ~ /^A/", []string{"Alice", "Anna"}},
+		{"name ~ /^b/i or country != UK", []string{"bob", "Anna"}},
+		{"name !~ /n/", []string{"Alice", "bob"}},
+		{"closed is null", []string{"Alice", "Anna"}},
+		{"not (closed is null)", []string{"bob"}},
+		{"closed is not null and closed > '2023-12-31'", []string{"bob"}},
+		{"country in ('FR', 'DE')", []string{"Anna"}},
+		{"Country not in (FR)", []string{"Alice", "bob"}},
+	}
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			expr, err := parseFilterExpression(c.input, []string{"name", "amount", "country", "closed"})
+			assert.Nil(t, err)
+			names := []string{}
+			for _, r := range filterRows {
+				if expr.match(r, filterKinds) {
+					names = append(names, r["name"])
+				}
+			}
+			assert.Equal(t, c.expected, names)
+		})
+	}
+}
+
+func TestFilterExpressionErrors(t *testing.T) {
+	cases := []struct {
+		input   string
+		message string
+	}{
+		{"amount >", "expected a value (at 9)"},
+		{"total > 5", "unknown column total (at 1)"},
+		{"amount = null", "use 'is null' to compare with null (at 10)"},
+		{"name ~ /(/", "invalid regex"},
+		{"(amount > 5", "expected ) (at 12)"},
+		{"name = 'open", "unterminated ' (at 8)"},
+		{"amount => 5", "unknown operator => (at 8)"},
+	}
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			_, err := parseFilterExpression(c.input, []string{"name", "amount"})
+			assert.NotNil(t, err)
+			assert.Contains(t, err.Error(), c.message)
+		})
+	}
+}
@@ -24,16 +24,36 @@ func (h *helpMenu) ToggleFullHelp() {
 }
 
 type navigationKeyMap struct {
-	Up              key.Binding
-	Down            key.Binding
-	Left            key.Binding
-	Right           key.Binding
-	Help            key.Binding
-	Quit            key.Binding
-	RegexFilter     key.Binding
-	SubstringFilter key.Binding
-	VisibleColumns  key.Binding
-	FilterColumns   key.Binding
+	Up               key.Binding
+	Down             key.Binding
+	Left             key.Binding
+	Right            key.Binding
+	Help             key.Binding
+	Quit             key.Binding
+	Back             key.Binding
+	RegexFilter      key.Binding
+	SubstringFilter  key.Binding
+	ExpressionFilter key.Binding
+	VisibleColumns   key.Binding
+	FilterColumns    key.Binding
+	Explain          key.Binding
+	MoveLeft         key.Binding
+	MoveRight        key.Binding
+	Pin              key.Binding
+	Widen            key.Binding
+	Narrow           key.Binding
+	FitAll           key.Binding
+	Wrap             key.Binding
+	GroupBy          key.Binding
+	Chart            key.Binding
+	NextMeasure      key.Binding
+	Search           key.Binding
+	NextMatch        key.Binding
+	PreviousMatch    key.Binding
+	Record           key.Binding
+	Profile          key.Binding
+	Sort             key.Binding
+	AddSort          key.Binding
 }
 
 func (k navigationKeyMap) ShortHelp() []key.Binding {
@@ -43,8 +63,12 @@ func (k navigationKeyMap) ShortHelp() []key.Binding {
 func (k navigationKeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Up, k.Down, k.Left, k.Right},
-		{k.VisibleColumns, k.FilterColumns, k.SubstringFilter, k.RegexFilter},
-		{k.Help, k.Quit},
+		{k.VisibleColumns, k.FilterColumns, k.SubstringFilter, k.RegexFilter, k.ExpressionFilter},
+		{k.Search, k.NextMatch, k.PreviousMatch},
+		{k.Record, k.Sort, k.AddSort, k.Profile, k.GroupBy, k.Chart, k.NextMeasure},
+		{k.MoveLeft, k.MoveRight, k.Pin},
+		{k.Widen, k.Narrow, k.FitAll, k.Wrap},
+		{k.Explain, k.Help, k.Back, k.Quit},
 	}
 }
 
@@ -74,11 +98,11 @@ var navigationKeys = navigationKeyMap{
 	),
 	Left: key.NewBinding(
 		key.WithKeys("left", "h"),
-		key.WithHelp("←/h", "move left"),
+		key.WithHelp("←/h", "previous column"),
 	),
 	Right: key.NewBinding(
 		key.WithKeys("right", "l"),
-		key.WithHelp("→/l", "move right"),
+		key.WithHelp("→/l", "next column"),
 	),
 	Help: key.NewBinding(
 		key.WithKeys("?"),
@@ -88,6 +112,10 @@ var navigationKeys = navigationKeyMap{
 		key.WithKeys("q", "ctrl+c"),
 		key.WithHelp("q", "quit"),
 	),
+	Back: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "back"),
+	),
 	RegexFilter: key.NewBinding(
 		key.WithKeys("\\"),
 		key.WithHelp("\\", "regex filter"),
@@ -96,6 +124,10 @@ var navigationKeys = navigationKeyMap{
 		key.WithKeys("/"),
 		key.WithHelp("/", "substring filter"),
 	),
+	ExpressionFilter: key.NewBinding(
+		key.WithKeys("f"),
+		key.WithHelp("f", "expression filter"),
+	),
 	VisibleColumns: key.NewBinding(
 		key.WithKeys("."),
 		key.WithHelp(".", "visible columns"),
@@ -104,16 +136,91 @@ var navigationKeys = navigationKeyMap{
 		key.WithKeys(","),
 		key.WithHelp(",", "filter columns"),
 	),
+	MoveLeft: key.NewBinding(
+		key.WithKeys("<"),
+		key.WithHelp("<", "move column left"),
+	),
+	MoveRight: key.NewBinding(
+		key.WithKeys(">"),
+		key.WithHelp(">", "move column right"),
+	),
+	Pin: key.NewBinding(
+		key.WithKeys("P"),
+		key.WithHelp("P", "pin/unpin column"),
+	),
+	Widen: key.NewBinding(
+		key.WithKeys("+"),
+		key.WithHelp("+", "widen column"),
+	),
+	Narrow: key.NewBinding(
+		key.WithKeys("-"),
+		key.WithHelp("-", "narrow column"),
+	),
+	FitAll: key.NewBinding(
+		key.WithKeys("="),
+		key.WithHelp("=", "fit all columns"),
+	),
+	Wrap: key.NewBinding(
+		key.WithKeys("w"),
+		key.WithHelp("w", "wrap/truncate"),
+	),
+	GroupBy: key.NewBinding(
+		key.WithKeys("g"),
+		key.WithHelp("g", "group by"),
+	),
+	Search: key.NewBinding(
+		key.WithKeys("ctrl+f"),
+		key.WithHelp("ctrl+f", "search"),
+	),
+	NextMatch: key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "next match"),
+	),
+	PreviousMatch: key.NewBinding(
+		key.WithKeys("N"),
+		key.WithHelp("N", "previous match"),
+	),
+	Chart: key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "chart column"),
+	),
+	NextMeasure: key.NewBinding(
+		key.WithKeys("tab"),
+		key.WithHelp("tab", "change chart measure"),
+	),
+	Record: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("↵", "view row"),
+	),
+	Profile: key.NewBinding(
+		key.WithKeys("p"),
+		key.WithHelp("p", "profile column"),
+	),
+	Sort: key.NewBinding(
+		key.WithKeys("s"),
+		key.WithHelp("s", "sort by column"),
+	),
+	AddSort: key.NewBinding(
+		key.WithKeys("S"),
+		key.WithHelp("S", "add sort key"),
+	),
+	Explain: key.NewBinding(
+		key.WithKeys("E"),
+		key.WithHelp("E", "explain query"),
+	),
 }
 
-var navigationHelpMenu = helpMenu{
-	keys:     navigationKeys,
-	help:     help.New(),
-	fullHelp: false,
+// keyHint describes a binding for a footer, e.g. "g regroup".
+func keyHint(b key.Binding, desc string) string {
+	return b.Help().Key + " " + desc
 }
 
-func newNavigationMenu() helpMenu {
-	return navigationHelpMenu
+func newHelpMenu(keys help.KeyMap) helpMenu {
+	return helpMenu{
+		keys:     keys,
+		help:     help.New(),
+		fullHelp: false,
+	}
 }
 
 var filteringKeys = filteringKeyMap{
@@ -127,12 +234,48 @@ var filteringKeys = filteringKeyMap{
 	),
 }
 
-var filteringHelpMenu = helpMenu{
-	keys:     filteringKeys,
-	help:     help.New(),
-	fullHelp: false,
+// columnListKeyMap holds the bindings of the column pickers, on top of the
+// list's own navigation and filtering keys.
+type columnListKeyMap struct {
+	Toggle    key.Binding
+	SelectAll key.Binding
+	ClearAll  key.Binding
+	MoveUp    key.Binding
+	MoveDown  key.Binding
+	Apply     key.Binding
+}
+
+func (k columnListKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Toggle, k.Apply}
+}
+
+func (k columnListKeyMap) FullHelp() []key.Binding {
+	return []key.Binding{k.Toggle, k.SelectAll, k.ClearAll, k.MoveUp, k.MoveDown, k.Apply}
 }
 
-func newFilteringMenu() helpMenu {
-	return filteringHelpMenu
+var columnListKeys = columnListKeyMap{
+	Toggle: key.NewBinding(
+		key.WithKeys(" "),
+		key.WithHelp("space", "toggle"),
+	),
+	SelectAll: key.NewBinding(
+		key.WithKeys("a"),
+		key.WithHelp("a", "select all"),
+	),
+	ClearAll: key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "clear all"),
+	),
+	MoveUp: key.NewBinding(
+		key.WithKeys("K", "shift+up"),
+		key.WithHelp("K", "move up"),
+	),
+	MoveDown: key.NewBinding(
+		key.WithKeys("J", "shift+down"),
+		key.WithHelp("J", "move down"),
+	),
+	Apply: key.NewBinding(
+		key.WithKeys("enter"),
+		key.WithHelp("↵", "apply"),
+	),
 }
@@ -0,0 +1,160 @@
+package sql
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// KeyMap holds the bindings of the result viewer. Update matches keys against
+// it and the help menus are rendered from it, so the two can't drift apart.
+type KeyMap struct {
+	Navigation navigationKeyMap
+	Filtering  filteringKeyMap
+	Columns    columnListKeyMap
+}
+
+func DefaultKeyMap() KeyMap {
+	return KeyMap{Navigation: navigationKeys, Filtering: filteringKeys, Columns: columnListKeys}
+}
+
+// NewKeyMap applies overrides, keyed by action name, to the default bindings
+// and reports every unknown action and conflicting key at once.
+func NewKeyMap(overrides map[string][]string) (KeyMap, error) {
+	keys := DefaultKeyMap()
+	navigation, filtering, columns := keys.navigationActions(), keys.filteringActions(), keys.columnActions()
+
+	var errs []error
+	for _, action := range slices.Sorted(maps.Keys(overrides)) {
+		binding, ok := navigation[action]
+		if !ok {
+			binding, ok = filtering[action]
+		}
+		if !ok {
+			binding, ok = columns[action]
+		}
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown key binding %q", action))
+			continue
+		}
+		if len(overrides[action]) == 0 {
+			errs = append(errs, fmt.Errorf("no keys given for %q", action))
+			continue
+		}
+		binding.SetKeys(overrides[action]...)
+		binding.SetHelp(helpKeys(overrides[action]), binding.Help().Desc)
+	}
+
+	errs = append(errs, conflicts(navigation)...)
+	errs = append(errs, conflicts(filtering)...)
+	errs = append(errs, conflicts(columns)...)
+	for _, action := range slices.Sorted(maps.Keys(filtering)) {
+		// anything printable would be typed into the filter instead
+		for _, k := range filtering[action].Keys() {
+			if utf8.RuneCountInString(k) == 1 {
+				errs = append(errs, fmt.Errorf("key %q of %q can't be used while typing", k, action))
+			}
+		}
+	}
+	return keys, errors.Join(errs...)
+}
+
+// conflicts reports keys bound to more than one of actions.
+func conflicts(actions map[string]*key.Binding) []error {
+	var errs []error
+	owners := map[string]string{}
+	for _, action := range slices.Sorted(maps.Keys(actions)) {
+		for _, k := range actions[action].Keys() {
+			if owner, ok := owners[k]; ok && owner != action {
+				errs = append(errs, fmt.Errorf("key %q is bound to both %q and %q", k, owner, action))
+				continue
+			}
+			owners[k] = action
+		}
+	}
+	return errs
+}
+
+var keySymbols = map[string]string{
+	"up":    "↑",
+	"down":  "↓",
+	"left":  "←",
+	"right": "→",
+	"enter": "↵",
+	" ":     "space",
+}
+
+// helpKeys describes keys the way the default help does, e.g. "↑/k".
+func helpKeys(keys []string) string {
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = k
+		if symbol, ok := keySymbols[k]; ok {
+			names[i] = symbol
+		}
+	}
+	return strings.Join(names, "/")
+}
+
+// navigationActions names the bindings of the table, as used in the config.
+func (k *KeyMap) navigationActions() map[string]*key.Binding {
+	n := &k.Navigation
+	return map[string]*key.Binding{
+		"up":                &n.Up,
+		"down":              &n.Down,
+		"left":              &n.Left,
+		"right":             &n.Right,
+		"help":              &n.Help,
+		"quit":              &n.Quit,
+		"back":              &n.Back,
+		"regex_filter":      &n.RegexFilter,
+		"substring_filter":  &n.SubstringFilter,
+		"expression_filter": &n.ExpressionFilter,
+		"visible_columns":   &n.VisibleColumns,
+		"filter_columns":    &n.FilterColumns,
+		"explain":           &n.Explain,
+		"move_left":         &n.MoveLeft,
+		"move_right":        &n.MoveRight,
+		"pin":               &n.Pin,
+		"widen":             &n.Widen,
+		"narrow":            &n.Narrow,
+		"fit_all":           &n.FitAll,
+		"wrap":              &n.Wrap,
+		"group_by":          &n.GroupBy,
+		"chart":             &n.Chart,
+		"next_measure":      &n.NextMeasure,
+		"search":            &n.Search,
+		"next_match":        &n.NextMatch,
+		"previous_match":    &n.PreviousMatch,
+		"record":            &n.Record,
+		"profile":           &n.Profile,
+		"sort":              &n.Sort,
+		"add_sort":          &n.AddSort,
+	}
+}
+
+// filteringActions names the bindings used while typing a filter or search.
+func (k *KeyMap) filteringActions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"apply": &k.Filtering.Apply,
+		"exit":  &k.Filtering.Exit,
+	}
+}
+
+// columnActions names the bindings of the column pickers.
+func (k *KeyMap) columnActions() map[string]*key.Binding {
+	c := &k.Columns
+	return map[string]*key.Binding{
+		"toggle_column":      &c.Toggle,
+		"select_all_columns": &c.SelectAll,
+		"clear_columns":      &c.ClearAll,
+		"move_column_up":     &c.MoveUp,
+		"move_column_down":   &c.MoveDown,
+		"apply_columns":      &c.Apply,
+	}
+}
@@ -0,0 +1,128 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewKeyMapOverrides(t *testing.T) {
+	keys, err := NewKeyMap(map[string][]string{"quit": {"Q", "ctrl+q"}, "up": {"up", "ctrl+p"}})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Q", "ctrl+q"}, keys.Navigation.Quit.Keys())
+	assert.Equal(t, "Q/ctrl+q", keys.Navigation.Quit.Help().Key)
+	assert.Equal(t, "↑/ctrl+p", keys.Navigation.Up.Help().Key)
+	assert.Equal(t, navigationKeys.Quit.Help().Desc, keys.Navigation.Quit.Help().Desc)
+}
+
+func TestNewKeyMapErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string][]string
+		message   string
+	}{
+		{"unknown action", map[string][]string{"teleport": {"t"}}, `unknown key binding "teleport"`},
+		{"no keys", map[string][]string{"sort": {}}, `no keys given for "sort"`},
+		{"conflict", map[string][]string{"chart": {"s"}}, `key "s" is bound to both "chart" and "sort"`},
+		{"typed while filtering", map[string][]string{"apply": {"a"}}, `key "a" of "apply" can't be used while typing`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewKeyMap(test.overrides)
+			assert.ErrorContains(t, err, test.message)
+		})
+	}
+}
+
+func TestQuitKeyIsTypedWhileFiltering(t *testing.T) {
+	data := []map[string]string{{"name": "quentin"}, {"name": "alice"}}
+	m := NewModel(data, []string{"name"}, nil, QueryContext{}, DefaultKeyMap())
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	assert.Nil(t, cmd)
+	assert.Equal(t, "q", m.textInput.Value())
+	assert.Len(t, m.filteredRows, 1)
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	_, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	assert.NotNil(t, cmd)
+}
+
+func TestReboundKeysInAggregateAndChart(t *testing.T) {
+	keys, err := NewKeyMap(map[string][]string{"group_by": {"G"}, "next_measure": {"m"}})
+	assert.Nil(t, err)
+	data := []map[string]string{{"city": "Utrecht", "n": "1"}, {"city": "Leiden", "n": "2"}}
+	m := NewModel(data, []string{"city", "n"}, nil, QueryContext{}, keys)
+
+	m.aggregate = newAggregateView(m.filteredRows, []string{"city"}, m.visibleCols, m.kinds)
+	m.aggregate.build(m.width, m.pageSize())
+	m.state = stateAggregate
+	assert.Contains(t, m.View(), "G regroup")
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'g'}})
+	assert.Equal(t, stateAggregate, m.state)
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'G'}})
+	assert.Equal(t, stateSelectGroupColumns, m.state)
+
+	m.chart = newChartView(m.filteredRows, "city", m.visibleCols, m.kinds)
+	m.state = stateChart
+	assert.Contains(t, m.View(), "m change measure")
+	m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	assert.Equal(t, "", m.chart.measure)
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'m'}})
+	assert.Equal(t, "n", m.chart.measure)
+}
+
+func TestReboundColumnPickerKeys(t *testing.T) {
+	keys, err := NewKeyMap(map[string][]string{"toggle_column": {"x"}, "back": {"backspace"}})
+	assert.Nil(t, err)
+	data := []map[string]string{{"city": "Utrecht", "country": "NL", "name": "Jan"}}
+	m := NewModel(data, []string{"city", "country", "name"}, nil, QueryContext{}, keys)
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'.'}})
+	m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	assert.True(t, m.listVisible.Items()[0].(columnItem).selected)
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	assert.False(t, m.listVisible.Items()[0].(columnItem).selected)
+
+	// typed into the list's filter, not a select all
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	assert.False(t, m.listVisible.Items()[0].(columnItem).selected)
+	assert.Equal(t, "na", m.listVisible.FilterValue())
+	runCmd(m, cmd)
+	assert.Equal(t, stateSelectVisibleColumns, m.state)
+
+	// toggles the filtered item rather than the first column
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	assert.False(t, m.listVisible.Items()[0].(columnItem).selected)
+	assert.False(t, m.listVisible.Items()[2].(columnItem).selected)
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Equal(t, stateSelectVisibleColumns, m.state)
+	m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	assert.Equal(t, list.Unfiltered, m.listVisible.FilterState())
+	m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	assert.Equal(t, stateNavigation, m.state)
+}
+
+// runCmd feeds the messages of cmd back into m, as the program would, e.g. the
+// matches of a list filter.
+func runCmd(m tea.Model, cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	switch msg := cmd().(type) {
+	case tea.BatchMsg:
+		for _, c := range msg {
+			runCmd(m, c)
+		}
+	case tea.KeyMsg, nil:
+	default:
+		m.Update(msg)
+	}
+}
@@ -0,0 +1,175 @@
+package sql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/databricks/databricks-sql-go/auth"
+	"github.com/databricks/databricks-sql-go/auth/oauth"
+	"github.com/databricks/databricks-sql-go/auth/oauth/m2m"
+	"github.com/databricks/databricks-sql-go/auth/oauth/u2m"
+	"golang.org/x/oauth2"
+)
+
+// The ways of authenticating with a workspace, as named by a profile's auth_type.
+const (
+	AuthPAT      = "pat"
+	AuthOAuthM2M = "oauth-m2m"
+	AuthOAuthU2M = "oauth-u2m"
+)
+
+// loginTimeout is how long the browser login of oauth-u2m waits for the user.
+const loginTimeout = 2 * time.Minute
+
+// u2mRedirectURL is where the workspace sends the browser after a login. The
+// workspaces only accept the clients and redirect registered for the driver.
+const u2mRedirectURL = "localhost:8030"
+
+var u2mClientIDs = map[oauth.CloudType]string{
+	oauth.AWS:   "databricks-sql-connector",
+	oauth.GCP:   "databricks-sql-connector",
+	oauth.Azure: "96eecda7-19ea-49cc-abb5-240097d554f5",
+}
+
+// newAuthenticator creates the authenticator for the auth type of c, or nil
+// for an access token.
+func (c *DatabricksConnection) newAuthenticator() (auth.Authenticator, error) {
+	switch c.AuthType {
+	case "", AuthPAT:
+		return nil, nil
+	case AuthOAuthM2M:
+		return &tokenAuthenticator{
+			path: c.tokenCachePath(),
+			newSource: func(cached *oauth2.Token) (oauth2.TokenSource, error) {
+				ctx := context.Background()
+				config, err := m2m.GetConfig(ctx, c.ServerHostname, c.ClientID, c.ClientSecret, m2m.GetScopes(c.ServerHostname, nil))
+				if err != nil {
+					return nil, err
+				}
+				// a new token is fetched with the client secret once the cached one expires
+				return oauth2.ReuseTokenSource(cached, config.TokenSource(ctx)), nil
+			},
+		}, nil
+	case AuthOAuthU2M:
+		clientID, ok := u2mClientIDs[oauth.InferCloudFromHost(c.ServerHostname)]
+		if !ok {
+			return nil, fmt.Errorf("oauth-u2m isn't supported for %s", c.ServerHostname)
+		}
+		return &tokenAuthenticator{
+			path: c.tokenCachePath(),
+			newSource: func(cached *oauth2.Token) (oauth2.TokenSource, error) {
+				ctx := context.Background()
+				config, err := u2m.GetConfig(ctx, c.ServerHostname, clientID, "", u2mRedirectURL, nil)
+				if err != nil {
+					return nil, err
+				}
+				// the refresh token saves logging in again until it expires
+				if cached != nil {
+					return config.TokenSource(ctx, cached), nil
+				}
+				provider, err := u2m.GetTokenSourceProvider(ctx, config, loginTimeout)
+				if err != nil {
+					return nil, err
+				}
+				return provider.GetTokenSource()
+			},
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown auth type %q", c.AuthType)
+}
+
+// tokenCachePath is the file keeping the OAuth token of c between runs.
+func (c *DatabricksConnection) tokenCachePath() string {
+	if c.TokenCacheDir == "" {
+		return ""
+	}
+	name := fmt.Sprintf("%s-%s", c.ServerHostname, c.AuthType)
+	if c.ClientID != "" {
+		name += "-" + c.ClientID
+	}
+	return filepath.Join(c.TokenCacheDir, name+".json")
+}
+
+// tokenAuthenticator authorizes requests with OAuth tokens, keeping the
+// latest token in a file so that it outlives the connection.
+type tokenAuthenticator struct {
+	path string
+	// newSource returns the tokens to use, starting from the cached token if
+	// there is one
+	newSource func(cached *oauth2.Token) (oauth2.TokenSource, error)
+
+	mx        sync.Mutex
+	tokens    oauth2.TokenSource
+	fromCache bool
+	saved     string
+}
+
+func (a *tokenAuthenticator) Authenticate(r *http.Request) error {
+	a.mx.Lock()
+	defer a.mx.Unlock()
+	if a.tokens == nil {
+		cached := a.load()
+		tokens, err := a.newSource(cached)
+		if err != nil {
+			return err
+		}
+		a.tokens, a.fromCache = tokens, cached != nil
+	}
+
+	token, err := a.tokens.Token()
+	if err != nil && a.fromCache {
+		// the cached token may have been revoked or its refresh token expired
+		a.fromCache = false
+		os.Remove(a.path)
+		a.tokens, err = a.newSource(nil)
+		if err == nil {
+			token, err = a.tokens.Token()
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("could not get an OAuth token: %w", err)
+	}
+	a.save(token)
+	token.SetAuthHeader(r)
+	return nil
+}
+
+func (a *tokenAuthenticator) load() *oauth2.Token {
+	if a.path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(a.path)
+	if err != nil {
+		return nil
+	}
+	var token oauth2.Token
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil
+	}
+	a.saved = token.AccessToken
+	return &token
+}
+
+// save caches token if it was fetched or refreshed since it was last saved.
+// Failing to cache only means fetching a token again next time.
+func (a *tokenAuthenticator) save(token *oauth2.Token) {
+	if a.path == "" || token.AccessToken == a.saved {
+		return
+	}
+	data, err := json.Marshal(token)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(a.path), 0700); err != nil {
+		return
+	}
+	if err := os.WriteFile(a.path, data, 0600); err == nil {
+		a.saved = token.AccessToken
+	}
+}
@@ -0,0 +1,96 @@
+package sql
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/databricks/databricks-sql-go/auth"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+// failingTokens is a token source whose refresh token was revoked.
+type failingTokens struct{}
+
+func (failingTokens) Token() (*oauth2.Token, error) { return nil, errors.New("invalid_grant") }
+
+func TestTokenAuthenticatorCachesTokens(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens", "host-oauth-u2m.json")
+	logins := 0
+	var cachedTokens []*oauth2.Token
+	newSource := func(cached *oauth2.Token) (oauth2.TokenSource, error) {
+		cachedTokens = append(cachedTokens, cached)
+		if cached != nil {
+			return oauth2.StaticTokenSource(cached), nil
+		}
+		logins++
+		return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "fresh", RefreshToken: "refresh", Expiry: time.Now().Add(time.Hour)}), nil
+	}
+
+	request, _ := http.NewRequest("GET", "https://host", nil)
+	first := &tokenAuthenticator{path: path, newSource: newSource}
+	assert.Nil(t, first.Authenticate(request))
+	assert.Equal(t, "Bearer fresh", request.Header.Get("Authorization"))
+	info, err := os.Stat(path)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// a later connection starts from the cached token instead of logging in
+	second := &tokenAuthenticator{path: path, newSource: newSource}
+	assert.Nil(t, second.Authenticate(request))
+	assert.Equal(t, 1, logins)
+	assert.Equal(t, "refresh", cachedTokens[1].RefreshToken)
+}
+
+func TestTokenAuthenticatorLogsInAgainWhenRefreshFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token.json")
+	assert.Nil(t, os.WriteFile(path, []byte(`{"access_token":"stale","refresh_token":"revoked"}`), 0600))
+	authenticator := &tokenAuthenticator{path: path, newSource: func(cached *oauth2.Token) (oauth2.TokenSource, error) {
+		if cached != nil {
+			return failingTokens{}, nil
+		}
+		return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "fresh"}), nil
+	}}
+
+	request, _ := http.NewRequest("GET", "https://host", nil)
+	assert.Nil(t, authenticator.Authenticate(request))
+	assert.Equal(t, "Bearer fresh", request.Header.Get("Authorization"))
+	data, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"access_token":"fresh"`)
+}
+
+func TestAuthenticatorForAuthType(t *testing.T) {
+	authenticator, err := (&DatabricksConnection{AuthType: AuthPAT}).authenticator()
+	assert.Nil(t, err)
+	assert.Nil(t, authenticator)
+
+	_, err = (&DatabricksConnection{AuthType: "basic"}).authenticator()
+	assert.EqualError(t, err, `unknown auth type "basic"`)
+
+	c := &DatabricksConnection{ServerHostname: "adb-1.azuredatabricks.net", AuthType: AuthOAuthM2M, ClientID: "sp", TokenCacheDir: "tokens"}
+	assert.Equal(t, filepath.Join("tokens", "adb-1.azuredatabricks.net-oauth-m2m-sp.json"), c.tokenCachePath())
+}
+
+func TestAuthenticatorIsSharedByQueries(t *testing.T) {
+	c := &DatabricksConnection{ServerHostname: "adb-1.azuredatabricks.net", AuthType: AuthOAuthU2M}
+	authenticators := make([]auth.Authenticator, 4)
+	var wg sync.WaitGroup
+	for i := range authenticators {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			authenticators[i], _ = c.authenticator()
+		}()
+	}
+	wg.Wait()
+	assert.NotNil(t, authenticators[0])
+	for _, a := range authenticators[1:] {
+		assert.Same(t, authenticators[0], a)
+	}
+}
@@ -0,0 +1,39 @@
+package sql
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// Ping runs SELECT 1 to check that a connection works, returning how long
+// the round trip took.
+func Ping(connection Connection, now func() time.Time) (time.Duration, error) {
+	start := now()
+	_, _, err := connection.RunQuery("SELECT 1")
+	if err != nil {
+		return 0, explainConnectionError(err)
+	}
+	return now().Sub(start), nil
+}
+
+// explainConnectionError says which setting of a profile is the likely cause
+// of a failed connection. The driver reports HTTP failures in the message only.
+func explainConnectionError(err error) error {
+	var dnsError *net.DNSError
+	var netError net.Error
+	message := err.Error()
+	switch {
+	case errors.As(err, &dnsError):
+		return fmt.Errorf("unknown host %s, check server_hostname: %w", dnsError.Name, err)
+	case errors.As(err, &netError) && netError.Timeout():
+		return fmt.Errorf("timed out reaching the host, check server_hostname: %w", err)
+	case strings.Contains(message, "HTTP Response code: 401"), strings.Contains(message, "HTTP Response code: 403"):
+		return fmt.Errorf("authentication failed, check the access token: %w", err)
+	case strings.Contains(message, "HTTP Response code: 404"):
+		return fmt.Errorf("warehouse not found, check http_path: %w", err)
+	}
+	return err
+}
@@ -0,0 +1,59 @@
+package sql
+
+import (
+	"database/sql"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type pingConnection struct {
+	err   error
+	query string
+}
+
+func (c *pingConnection) Query(sqlString string) (*sql.Rows, error) { return nil, nil }
+func (c *pingConnection) RunQuery(sqlString string) ([]map[string]string, []string, error) {
+	c.query = sqlString
+	return nil, nil, c.err
+}
+func (c *pingConnection) RunQueryFromFile(filePath string) ([]map[string]string, []string, map[string]string, error) {
+	return nil, nil, nil, nil
+}
+
+func TestPingReportsLatency(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	times := []time.Time{start, start.Add(250 * time.Millisecond)}
+	now := func() time.Time {
+		t := times[0]
+		times = times[1:]
+		return t
+	}
+	connection := &pingConnection{}
+
+	latency, err := Ping(connection, now)
+	assert.Nil(t, err)
+	assert.Equal(t, 250*time.Millisecond, latency)
+	assert.Equal(t, "SELECT 1", connection.query)
+}
+
+func TestPingExplainsErrors(t *testing.T) {
+	tests := []struct {
+		err     error
+		message string
+	}{
+		{&net.DNSError{Name: "adb-1.net", Err: "no such host"}, "unknown host adb-1.net, check server_hostname"},
+		{errors.New("failed to open session: HTTP Response code: 401"), "authentication failed, check the access token"},
+		{errors.New("HTTP Response code: 404"), "warehouse not found, check http_path"},
+	}
+	for _, test := range tests {
+		_, err := Ping(&pingConnection{err: test.err}, time.Now)
+		assert.ErrorContains(t, err, test.message)
+		assert.ErrorIs(t, err, test.err)
+	}
+	_, err := Ping(&pingConnection{err: errors.New("syntax")}, time.Now)
+	assert.EqualError(t, err, "syntax")
+}
@@ -0,0 +1,127 @@
+package sql
+
+import (
+	"regexp"
+	"strings"
+)
+
+// PlanNode is one operator of a query plan, or a "== Section ==" heading
+// whose children are the operators of that section.
+type PlanNode struct {
+	Text     string
+	Details  []string
+	Children []*PlanNode
+	Section  bool
+	FullScan bool
+	Exchange bool
+	// InputBytes and InputRows estimate what an exchange sends, from the
+	// statistics of EXPLAIN COST, and LargeExchange marks one sending more
+	// than largeExchangeBytes
+	InputBytes    float64
+	InputRows     float64
+	LargeExchange bool
+}
+
+var (
+	planSectionHeading = regexp.MustCompile(`^== (.+) ==$`)
+	planDetailHeading  = regexp.MustCompile(`^\((\d+)\) `)
+	planNodeID         = regexp.MustCompile(`\((\d+)\)$`)
+	planAppliedFilter  = regexp.MustCompile(`(PartitionFilters|PushedFilters|DataFilters): \[[^\]]`)
+)
+
+// planLine splits a tree line into its depth and operator text. Children are
+// drawn three characters to the right of their parent using "+- " and ":- ".
+func planLine(line string) (int, string) {
+	start := 0
+	for start < len(line) {
+		rest := line[start:]
+		if strings.HasPrefix(rest, "+- ") || strings.HasPrefix(rest, ":- ") {
+			start += 3
+			break
+		}
+		if !strings.ContainsRune(" :|", rune(line[start])) {
+			break
+		}
+		start++
+	}
+	return start / 3, strings.TrimSpace(line[start:])
+}
+
+func (n *PlanNode) classify() {
+	text := n.Text + "\n" + strings.Join(n.Details, "\n")
+	isScan := strings.Contains(n.Text, "Scan ") || strings.HasPrefix(n.Text, "Scan")
+	n.FullScan = isScan && !planAppliedFilter.MatchString(text)
+	n.Exchange = strings.Contains(n.Text, "Exchange") && !strings.Contains(n.Text, "BroadcastExchange")
+}
+
+// ParsePlan turns EXPLAIN output into a tree. Output with "== Section =="
+// headings gets one root per section; EXPLAIN FORMATTED detail blocks are
+// attached to the operator with the matching "(n)" id.
+func ParsePlan(text string) []*PlanNode {
+	var roots []*PlanNode
+	var section *PlanNode
+	var stack []*PlanNode
+	byID := map[string]*PlanNode{}
+	var details *PlanNode
+	inTree := true
+
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if match := planSectionHeading.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+			section = &PlanNode{Text: match[1], Section: true}
+			roots = append(roots, section)
+			stack = nil
+			details = nil
+			inTree = true
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			if len(stack) > 0 {
+				inTree = false
+			}
+			details = nil
+			continue
+		}
+
+		if !inTree {
+			if match := planDetailHeading.FindStringSubmatch(line); match != nil {
+				details = byID[match[1]]
+			} else if details != nil {
+				details.Details = append(details.Details, strings.TrimSpace(line))
+			}
+			continue
+		}
+
+		depth, operator := planLine(line)
+		node := &PlanNode{Text: operator}
+		if match := planNodeID.FindStringSubmatch(operator); match != nil {
+			byID[match[1]] = node
+		}
+		for len(stack) > depth {
+			stack = stack[:len(stack)-1]
+		}
+		switch {
+		case len(stack) > 0:
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, node)
+		case section != nil:
+			section.Children = append(section.Children, node)
+		default:
+			roots = append(roots, node)
+		}
+		for len(stack) < depth {
+			// Malformed indentation: treat the node as a child of the deepest one.
+			stack = append(stack, node)
+		}
+		stack = append(stack[:depth], node)
+	}
+
+	var walk func(nodes []*PlanNode)
+	walk = func(nodes []*PlanNode) {
+		for _, n := range nodes {
+			n.classify()
+			walk(n.Children)
+		}
+	}
+	walk(roots)
+	return roots
+}
@@ -0,0 +1,140 @@
+package sql
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// largeExchangeBytes is the estimated input from which an exchange is
+// highlighted. Sending a gigabyte between executors is worth a second look.
+const largeExchangeBytes = 1 << 30
+
+// unknownSizeBytes is what EXPLAIN COST reports for an operator it has no
+// statistics for.
+const unknownSizeBytes = 8 << 60
+
+var (
+	planStatistics   = regexp.MustCompile(`Statistics\(sizeInBytes=([\d.]+) ?([KMGTPE]i)?B(?:, rowCount=([\d.E+-]+))?`)
+	planPartitioning = regexp.MustCompile(`(?:hash|range)partitioning\(([^)]*)\)`)
+	planAttribute    = regexp.MustCompile(`(\w+)#\d+`)
+	planScanTable    = regexp.MustCompile(`Scan \w+ ([\w.]+)`)
+	planRelation     = regexp.MustCompile(`Relation ([\w.]+)\[`)
+)
+
+var planSizeUnits = map[string]float64{"": 1, "Ki": 1 << 10, "Mi": 1 << 20, "Gi": 1 << 30, "Ti": 1 << 40, "Pi": 1 << 50, "Ei": 1 << 60}
+
+// exchangingOperators are the logical operators that the physical plan feeds
+// through an exchange.
+var exchangingOperators = []string{"Aggregate", "Join", "Sort", "Window", "Repartition", "RepartitionByExpression", "Deduplicate"}
+
+// parseStatistics reads the estimate EXPLAIN COST gives for an operator. The
+// row count is 0 when it isn't known.
+func parseStatistics(text string) (float64, float64, bool) {
+	match := planStatistics.FindStringSubmatch(text)
+	if match == nil {
+		return 0, 0, false
+	}
+	size, err := strconv.ParseFloat(match[1], 64)
+	size *= planSizeUnits[match[2]]
+	if err != nil || size >= unknownSizeBytes {
+		return 0, 0, false
+	}
+	rows, _ := strconv.ParseFloat(match[3], 64)
+	return size, rows, true
+}
+
+// EstimateExchanges sizes the exchanges of a plan with the statistics of the
+// optimized logical plan in cost, the output of EXPLAIN COST for the same
+// query. The two are planned separately, so an exchange is matched with the
+// logical operator that reads the same tables and names its partitioning
+// columns. The input of that operator is what the exchange sends, or at most
+// that when the aggregation is partly done before it.
+func EstimateExchanges(roots []*PlanNode, cost string) {
+	var logical []*PlanNode
+	for _, root := range ParsePlan(cost) {
+		if root.Section && root.Text == "Optimized Logical Plan" {
+			logical = root.Children
+		}
+	}
+	if len(logical) == 0 {
+		return
+	}
+	walkPlan(roots, func(n *PlanNode) {
+		if !n.Exchange {
+			return
+		}
+		tables := tablesRead(n, planScanTable)
+		keys := partitionColumns(n)
+		walkPlan(logical, func(l *PlanNode) {
+			operator, _, _ := strings.Cut(l.Text, " ")
+			if !slices.Contains(exchangingOperators, operator) || !mentionsColumns(l.Text, keys) {
+				return
+			}
+			for _, child := range l.Children {
+				if !slices.Equal(tablesRead(child, planRelation), tables) {
+					continue
+				}
+				if size, rows, ok := parseStatistics(child.Text); ok && size > n.InputBytes {
+					n.InputBytes, n.InputRows = size, rows
+				}
+			}
+		})
+		n.LargeExchange = n.InputBytes >= largeExchangeBytes
+	})
+}
+
+func walkPlan(nodes []*PlanNode, visit func(*PlanNode)) {
+	for _, n := range nodes {
+		visit(n)
+		walkPlan(n.Children, visit)
+	}
+}
+
+// tablesRead lists the tables scanned under n, as found by pattern.
+func tablesRead(n *PlanNode, pattern *regexp.Regexp) []string {
+	var tables []string
+	walkPlan([]*PlanNode{n}, func(c *PlanNode) {
+		if match := pattern.FindStringSubmatch(c.Text); match != nil && !slices.Contains(tables, match[1]) {
+			tables = append(tables, match[1])
+		}
+	})
+	slices.Sort(tables)
+	return tables
+}
+
+// partitionColumns names the columns an exchange partitions by, without the
+// attribute ids that differ between two runs of EXPLAIN.
+func partitionColumns(n *PlanNode) []string {
+	match := planPartitioning.FindStringSubmatch(n.Text + "\n" + strings.Join(n.Details, "\n"))
+	if match == nil {
+		return nil
+	}
+	var columns []string
+	for _, attribute := range planAttribute.FindAllStringSubmatch(match[1], -1) {
+		columns = append(columns, attribute[1])
+	}
+	return columns
+}
+
+func mentionsColumns(text string, columns []string) bool {
+	for _, c := range columns {
+		if !regexp.MustCompile(`\b` + regexp.QuoteMeta(c) + `#\d+`).MatchString(text) {
+			return false
+		}
+	}
+	return true
+}
+
+// formatBytes shows a size in binary units, e.g. "1.5 GiB".
+func formatBytes(size float64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB"}
+	i := 0
+	for size >= 1024 && i < len(units)-1 {
+		size /= 1024
+		i++
+	}
+	return fmt.Sprintf("%.1f %s", size, units[i])
+}
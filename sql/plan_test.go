@@ -0,0 +1,126 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const formattedPlan = `== Physical Plan ==
+AdaptiveSparkPlan (6)
++- HashAggregate (5)
+   +- Exchange (4)
+      +- HashAggregate (3)
+         :- Filter (2)
+         :  +- Scan parquet main.sales.orders (1)
+         +- Scan parquet main.sales.customers (7)
+
+
+(1) Scan parquet main.sales.orders
+Output [2]: [id#1, amount#2]
+PushedFilters: [IsNotNull(amount)]
+
+(4) Exchange
+Input [2]: [id#1, count#3]
+Arguments: hashpartitioning(id#1, 200), ENSURE_REQUIREMENTS, [plan_id=10]
+
+(7) Scan parquet main.sales.customers
+Output [1]: [id#4]
+PushedFilters: []
+`
+
+func TestParsePlanFormatted(t *testing.T) {
+	roots := ParsePlan(formattedPlan)
+
+	assert.Len(t, roots, 1)
+	assert.True(t, roots[0].Section)
+	root := roots[0].Children[0]
+	assert.Equal(t, "AdaptiveSparkPlan (6)", root.Text)
+
+	exchange := root.Children[0].Children[0]
+	assert.True(t, exchange.Exchange)
+	assert.Contains(t, exchange.Details, "Arguments: hashpartitioning(id#1, 200), ENSURE_REQUIREMENTS, [plan_id=10]")
+
+	partial := exchange.Children[0]
+	assert.Len(t, partial.Children, 2)
+	filteredScan := partial.Children[0].Children[0]
+	assert.Equal(t, "Scan parquet main.sales.orders (1)", filteredScan.Text)
+	assert.False(t, filteredScan.FullScan)
+	assert.True(t, partial.Children[1].FullScan)
+}
+
+func TestParsePlanExtendedSections(t *testing.T) {
+	plan := `== Parsed Logical Plan ==
+'Project [*]
++- 'UnresolvedRelation [orders]
+
+== Physical Plan ==
+*(1) Project [id#1]
++- BroadcastExchange HashedRelationBroadcastMode
+   +- FileScan parquet [id#1] PartitionFilters: [isnotnull(day#5)], PushedFilters: []`
+
+	roots := ParsePlan(plan)
+
+	assert.Len(t, roots, 2)
+	assert.Equal(t, "Parsed Logical Plan", roots[0].Text)
+	assert.Equal(t, "'UnresolvedRelation [orders]", roots[0].Children[0].Children[0].Text)
+	broadcast := roots[1].Children[0].Children[0]
+	assert.False(t, broadcast.Exchange)
+	assert.False(t, broadcast.Children[0].FullScan)
+}
+
+const aggregatePlan = `== Physical Plan ==
+AdaptiveSparkPlan (5)
++- HashAggregate (4)
+   +- Exchange (3)
+      +- HashAggregate (2)
+         +- Scan parquet main.sales.orders (1)
+
+
+(3) Exchange
+Input [2]: [id#1, count#3]
+Arguments: hashpartitioning(id#1, 200), ENSURE_REQUIREMENTS, [plan_id=10]
+`
+
+// aggregateCost is EXPLAIN COST of the same query, whose attribute ids differ.
+func aggregateCost(projectSize string) string {
+	return `== Optimized Logical Plan ==
+Aggregate [id#21], [id#21, count(1) AS count#23], Statistics(sizeInBytes=1.0 MiB, rowCount=5.00E+4)
++- Project [id#21], Statistics(sizeInBytes=` + projectSize + `, rowCount=2.00E+8)
+   +- Relation main.sales.orders[id#21,amount#22] parquet, Statistics(sizeInBytes=6.0 GiB, rowCount=2.00E+8)
+
+== Physical Plan ==
+*(2) HashAggregate(keys=[id#21], functions=[count(1)])
++- Exchange hashpartitioning(id#21, 200), ENSURE_REQUIREMENTS, [plan_id=30]
+`
+}
+
+func TestEstimateExchanges(t *testing.T) {
+	tests := []struct {
+		name        string
+		projectSize string
+		inputBytes  float64
+		large       bool
+	}{
+		{"over the threshold", "3.0 GiB", 3 << 30, true},
+		{"under the threshold", "512.0 MiB", 512 << 20, false},
+		{"no statistics", "8.0 EiB", 0, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			roots := ParsePlan(aggregatePlan)
+			EstimateExchanges(roots, aggregateCost(test.projectSize))
+
+			exchange := roots[0].Children[0].Children[0].Children[0]
+			assert.True(t, exchange.Exchange)
+			assert.Equal(t, test.inputBytes, exchange.InputBytes)
+			assert.Equal(t, test.large, exchange.LargeExchange)
+		})
+	}
+}
+
+func TestPlanViewHighlightsLargeExchanges(t *testing.T) {
+	view := newPlanView(aggregatePlan, aggregateCost("3.0 GiB"), 20)
+	assert.Contains(t, view.View(), "[large exchange: ~3.0 GiB, 2.0e+08 rows]")
+	assert.Contains(t, view.View(), "1 large exchanges")
+}
@@ -0,0 +1,255 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type planKeyMap struct {
+	Up      key.Binding
+	Down    key.Binding
+	Toggle  key.Binding
+	Details key.Binding
+	Exit    key.Binding
+}
+
+func (k planKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Toggle, k.Details, k.Exit}
+}
+
+func (k planKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Up, k.Down},
+		{k.Toggle, k.Details, k.Exit},
+	}
+}
+
+var planKeys = planKeyMap{
+	Up: key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("↑/k", "move up"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("↓/j", "move down"),
+	),
+	Toggle: key.NewBinding(
+		key.WithKeys("enter", " "),
+		key.WithHelp("↵", "expand/collapse"),
+	),
+	Details: key.NewBinding(
+		key.WithKeys("d"),
+		key.WithHelp("d", "details"),
+	),
+	Exit: key.NewBinding(
+		key.WithKeys("esc", "q"),
+		key.WithHelp("esc", "close"),
+	),
+}
+
+type planRow struct {
+	node   *PlanNode
+	depth  int
+	detail string // set for detail lines shown beneath an operator
+}
+
+// planView shows a parsed plan as a collapsible tree. Logical plan sections
+// start collapsed so the physical plan is visible straight away.
+type planView struct {
+	roots       []*PlanNode
+	collapsed   map[*PlanNode]bool
+	showDetails map[*PlanNode]bool
+	rows        []planRow
+	cursor      int
+	offset      int
+	height      int
+	help        help.Model
+	closed      bool
+}
+
+// newPlanView shows plan, with its exchanges sized from cost, the output of
+// EXPLAIN COST, when that could be fetched.
+func newPlanView(plan string, cost string, height int) planView {
+	v := planView{
+		roots:       ParsePlan(plan),
+		collapsed:   map[*PlanNode]bool{},
+		showDetails: map[*PlanNode]bool{},
+		height:      height,
+		help:        help.New(),
+	}
+	EstimateExchanges(v.roots, cost)
+	for _, root := range v.roots {
+		if root.Section && strings.Contains(root.Text, "Logical Plan") {
+			v.collapsed[root] = true
+		}
+	}
+	v.refresh()
+	return v
+}
+
+func (v *planView) refresh() {
+	var rows []planRow
+	var walk func(nodes []*PlanNode, depth int)
+	walk = func(nodes []*PlanNode, depth int) {
+		for _, n := range nodes {
+			rows = append(rows, planRow{node: n, depth: depth})
+			if v.showDetails[n] {
+				for _, d := range n.Details {
+					rows = append(rows, planRow{node: n, depth: depth + 1, detail: d})
+				}
+			}
+			if !v.collapsed[n] {
+				walk(n.Children, depth+1)
+			}
+		}
+	}
+	walk(v.roots, 0)
+	v.rows = rows
+	v.moveCursor(0)
+}
+
+func (v *planView) moveCursor(delta int) {
+	v.cursor = max(0, min(v.cursor+delta, len(v.rows)-1))
+	if v.cursor < v.offset {
+		v.offset = v.cursor
+	} else if v.cursor >= v.offset+v.height {
+		v.offset = v.cursor - v.height + 1
+	}
+}
+
+func (v planView) Update(msg tea.Msg) (planView, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		v.height = max(1, msg.Height-3)
+		v.moveCursor(0)
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, planKeys.Up):
+			v.moveCursor(-1)
+		case key.Matches(msg, planKeys.Down):
+			v.moveCursor(1)
+		case key.Matches(msg, planKeys.Toggle):
+			if len(v.rows) > 0 {
+				n := v.rows[v.cursor].node
+				v.collapsed[n] = !v.collapsed[n]
+				v.refresh()
+			}
+		case key.Matches(msg, planKeys.Details):
+			if len(v.rows) > 0 {
+				n := v.rows[v.cursor].node
+				v.showDetails[n] = !v.showDetails[n]
+				v.refresh()
+			}
+		case key.Matches(msg, planKeys.Exit):
+			v.closed = true
+		}
+	}
+	return v, nil
+}
+
+func (v planView) renderRow(row planRow) string {
+	indent := strings.Repeat("  ", row.depth)
+	if row.detail != "" {
+		return indent + planDetailStyle.Render(row.detail)
+	}
+	n := row.node
+	marker := "  "
+	if len(n.Children) > 0 {
+		marker = "▾ "
+		if v.collapsed[n] {
+			marker = "▸ "
+		}
+	}
+	switch {
+	case n.Section:
+		return indent + marker + planSectionStyle.Render(n.Text)
+	case n.FullScan:
+		return indent + marker + planFullScanStyle.Render(n.Text+"  [full scan]")
+	case n.LargeExchange:
+		return indent + marker + planLargeExchangeStyle.Render(n.Text+"  [large exchange: "+describeExchange(n)+"]")
+	case n.Exchange && n.InputBytes > 0:
+		return indent + marker + planExchangeStyle.Render(n.Text+"  [exchange: "+describeExchange(n)+"]")
+	case n.Exchange:
+		return indent + marker + planExchangeStyle.Render(n.Text+"  [exchange]")
+	}
+	return indent + marker + n.Text
+}
+
+func (v planView) View() string {
+	var b strings.Builder
+	if len(v.rows) == 0 {
+		b.WriteString("No plan to display.\n")
+	}
+	end := min(v.offset+v.height, len(v.rows))
+	for i := v.offset; i < end; i++ {
+		line := v.renderRow(v.rows[i])
+		if i == v.cursor {
+			line = planCursorStyle.Render(line)
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	fullScans, largeExchanges := v.counts()
+	b.WriteString(fmt.Sprintf("%d full scans, %d large exchanges\n", fullScans, largeExchanges))
+	b.WriteString(v.help.View(planKeys))
+	return b.String()
+}
+
+func (v planView) counts() (int, int) {
+	fullScans, largeExchanges := 0, 0
+	var walk func(nodes []*PlanNode)
+	walk = func(nodes []*PlanNode) {
+		for _, n := range nodes {
+			if n.FullScan {
+				fullScans++
+			}
+			if n.LargeExchange {
+				largeExchanges++
+			}
+			walk(n.Children)
+		}
+	}
+	walk(v.roots)
+	return fullScans, largeExchanges
+}
+
+// planProgram wraps planView for standalone use by the explain command.
+type planProgram struct {
+	view planView
+}
+
+func (p planProgram) Init() tea.Cmd { return nil }
+
+func (p planProgram) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok && key.String() == "ctrl+c" {
+		return p, tea.Quit
+	}
+	var cmd tea.Cmd
+	p.view, cmd = p.view.Update(msg)
+	if p.view.closed {
+		return p, tea.Quit
+	}
+	return p, cmd
+}
+
+func (p planProgram) View() string { return p.view.View() }
+
+// describeExchange shows the estimated input of an exchange, e.g. "~1.5 GiB, 2.0e+07 rows".
+func describeExchange(n *PlanNode) string {
+	s := "~" + formatBytes(n.InputBytes)
+	if n.InputRows > 0 {
+		s += fmt.Sprintf(", %.1e rows", n.InputRows)
+	}
+	return s
+}
+
+// PrintPlanTea shows the plan text produced by Explain as an interactive
+// tree, sizing its exchanges with cost as newPlanView does.
+func PrintPlanTea(plan string, cost string) error {
+	_, err := tea.NewProgram(planProgram{newPlanView(plan, cost, 20)}, tea.WithAltScreen()).Run()
+	return err
+}
@@ -0,0 +1,72 @@
+package sql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const recordNameWidth = 30
+
+// prettyValue indents JSON objects and arrays, which is how struct, map and
+// array columns arrive. Anything else is returned unchanged.
+func prettyValue(value string) string {
+	trimmed := strings.TrimSpace(value)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return value
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, []byte(trimmed), "", "  "); err != nil {
+		return value
+	}
+	return out.String()
+}
+
+// renderRecord lays a row out vertically as name: value pairs, wrapping long
+// values to fit width.
+func renderRecord(r Record, cols []string, width int) string {
+	nameWidth := 0
+	for _, c := range cols {
+		nameWidth = max(nameWidth, min(lipgloss.Width(c), recordNameWidth))
+	}
+	valueStyle := lipgloss.NewStyle().Width(max(10, width-nameWidth-2))
+
+	var b strings.Builder
+	for _, c := range cols {
+		name := recordNameStyle.Width(nameWidth).Render(c)
+		value := valueStyle.Render(prettyValue(r[c]))
+		b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, name, "  ", value))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// recordView scrolls through one row at a time.
+type recordView struct {
+	viewport viewport.Model
+	index    int
+}
+
+func newRecordView(width int, height int) recordView {
+	return recordView{viewport: viewport.New(width, height)}
+}
+
+func (v *recordView) show(rows []Record, cols []string, index int) {
+	if len(rows) == 0 {
+		return
+	}
+	v.index = max(0, min(index, len(rows)-1))
+	v.viewport.SetContent(renderRecord(rows[v.index], cols, v.viewport.Width))
+	v.viewport.GotoTop()
+}
+
+func (v recordView) View(total int, keys navigationKeyMap) string {
+	header := headerStyle.Render(fmt.Sprintf("Row %d of %d", v.index+1, total))
+	footer := statusStyle.Render(fmt.Sprintf("%3.f%% · %s · %s · %s", v.viewport.ScrollPercent()*100,
+		keyHint(keys.Left, "previous row"), keyHint(keys.Right, "next row"), keyHint(keys.Back, "back")))
+	return header + "\n" + v.viewport.View() + "\n" + footer
+}
@@ -0,0 +1,21 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrettyValue(t *testing.T) {
+	assert.Equal(t, "{\n  \"a\": [\n    1,\n    2\n  ]\n}", prettyValue(`{"a":[1,2]}`))
+	assert.Equal(t, "[not json", prettyValue("[not json"))
+	assert.Equal(t, "plain", prettyValue("plain"))
+}
+
+func TestRenderRecordShowsFullValues(t *testing.T) {
+	long := "a value that is much longer than the twenty characters a table column allows"
+	out := renderRecord(Record{"id": "1", "description": long}, []string{"id", "description"}, 200)
+
+	assert.Contains(t, out, long)
+	assert.Contains(t, out, "description")
+}
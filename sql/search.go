@@ -0,0 +1,94 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// searchMatch is a cell of filteredRows that contains the search text.
+type searchMatch struct {
+	row    int
+	column string
+}
+
+// cellMatches reports whether value contains query. Like vim's smartcase, the
+// search ignores case unless the query has an upper case letter.
+func cellMatches(value string, query string) bool {
+	if query == "" {
+		return false
+	}
+	if strings.IndexFunc(query, unicode.IsUpper) < 0 {
+		value = strings.ToLower(value)
+	}
+	return strings.Contains(value, query)
+}
+
+// findMatches collects every visible cell matching the search, row by row.
+func (m *model) findMatches() {
+	m.matches = nil
+	for i, r := range m.filteredRows {
+		for _, c := range m.visibleCols {
+			if cellMatches(r[c], m.search) {
+				m.matches = append(m.matches, searchMatch{i, c})
+			}
+		}
+	}
+	m.matchIndex = max(0, min(m.matchIndex, len(m.matches)-1))
+}
+
+// currentMatch is the match jumped to last, if there is one.
+func (m *model) currentMatch() (searchMatch, bool) {
+	if len(m.matches) == 0 {
+		return searchMatch{}, false
+	}
+	return m.matches[m.matchIndex], true
+}
+
+// jumpToMatch moves the cursor to match i, wrapping around at either end.
+func (m *model) jumpToMatch(i int) {
+	if len(m.matches) == 0 {
+		return
+	}
+	m.matchIndex = (i%len(m.matches) + len(m.matches)) % len(m.matches)
+	match := m.matches[m.matchIndex]
+	for ci, c := range m.visibleCols {
+		if c == match.column {
+			m.colCursor = ci
+		}
+	}
+	m.buildTable(m.filteredRows)
+	m.table = m.table.WithHighlightedRow(match.row)
+}
+
+// jumpToFirstMatch jumps to the first match at or below the highlighted row.
+func (m *model) jumpToFirstMatch() {
+	row := m.table.GetHighlightedRowIndex()
+	for i, match := range m.matches {
+		if match.row >= row {
+			m.jumpToMatch(i)
+			return
+		}
+	}
+	m.jumpToMatch(0)
+}
+
+// matchStyle is how a cell is highlighted by the search, if at all.
+func (m *model) matchStyle(row int, column string, value string) (lipgloss.Style, bool) {
+	if !cellMatches(value, m.search) {
+		return lipgloss.Style{}, false
+	}
+	if current, ok := m.currentMatch(); ok && current.row == row && current.column == column {
+		return searchCurrentStyle, true
+	}
+	return searchMatchStyle, true
+}
+
+func (m *model) searchStatus() string {
+	if len(m.matches) == 0 {
+		return fmt.Sprintf("search: %s (no matches)", m.search)
+	}
+	return fmt.Sprintf("search: %s [%d/%d]", m.search, m.matchIndex+1, len(m.matches))
+}
@@ -0,0 +1,50 @@
+package sql
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCellMatchesSmartCase(t *testing.T) {
+	assert.True(t, cellMatches("Amsterdam", "ams"))
+	assert.True(t, cellMatches("Amsterdam", "Ams"))
+	assert.False(t, cellMatches("amsterdam", "Ams"))
+	assert.False(t, cellMatches("anything", ""))
+}
+
+func TestSearchJumpsBetweenMatches(t *testing.T) {
+	data := []map[string]string{
+		{"id": "1", "city": "Utrecht", "country": "NL"},
+		{"id": "2", "city": "Leiden", "country": "NL"},
+		{"id": "3", "city": "Lyon", "country": "FR"},
+		{"id": "4", "city": "Leuven", "country": "BE"},
+	}
+	m := NewModel(data, []string{"id", "city", "country"}, nil, QueryContext{}, DefaultKeyMap())
+
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlF})
+	assert.Equal(t, stateSearching, m.state)
+	for _, r := range "le" {
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	// every row is still shown, the cursor is on the first match
+	assert.Len(t, m.filteredRows, 4)
+	assert.Equal(t, []searchMatch{{1, "city"}, {3, "city"}}, m.matches)
+	assert.Equal(t, 1, m.table.GetHighlightedRowIndex())
+	assert.Equal(t, "city", m.currentColumn())
+	assert.Contains(t, m.statusLine(), "[1/2]")
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	assert.Equal(t, 3, m.table.GetHighlightedRowIndex())
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	assert.Equal(t, 1, m.table.GetHighlightedRowIndex())
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'N'}})
+	assert.Equal(t, 3, m.table.GetHighlightedRowIndex())
+	assert.Contains(t, m.statusLine(), "[2/2]")
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Empty(t, m.matches)
+}
@@ -0,0 +1,82 @@
+package sql
+
+import (
+	"fmt"
+	"slices"
+)
+
+type sortKey struct {
+	column     string
+	descending bool
+}
+
+// toggleSortKey cycles column through ascending, descending and unsorted. When
+// additive is false the column replaces every other key, otherwise it is added
+// to the end of the existing keys.
+func toggleSortKey(keys []sortKey, column string, additive bool) []sortKey {
+	index := slices.IndexFunc(keys, func(k sortKey) bool { return k.column == column })
+	if !additive {
+		if index < 0 || len(keys) > 1 {
+			return []sortKey{{column: column}}
+		}
+		if !keys[index].descending {
+			return []sortKey{{column: column, descending: true}}
+		}
+		return nil
+	}
+	keys = slices.Clone(keys)
+	switch {
+	case index < 0:
+		return append(keys, sortKey{column: column})
+	case !keys[index].descending:
+		keys[index].descending = true
+		return keys
+	}
+	return slices.Delete(keys, index, index+1)
+}
+
+// sortRecords stably orders rows by keys. NULLs sort last in either direction.
+func sortRecords(rows []Record, keys []sortKey, kinds map[string]valueKind) {
+	if len(keys) == 0 {
+		return
+	}
+	slices.SortStableFunc(rows, func(a, b Record) int {
+		for _, k := range keys {
+			x, y := a[k.column], b[k.column]
+			switch {
+			case isNull(x) && isNull(y):
+				continue
+			case isNull(x):
+				return 1
+			case isNull(y):
+				return -1
+			}
+			result := compareValues(x, y, kinds[k.column])
+			if k.descending {
+				result = -result
+			}
+			if result != 0 {
+				return result
+			}
+		}
+		return 0
+	})
+}
+
+// sortIndicator is appended to a column title to show its place in the sort.
+func sortIndicator(keys []sortKey, column string) string {
+	for i, k := range keys {
+		if k.column != column {
+			continue
+		}
+		arrow := "▲"
+		if k.descending {
+			arrow = "▼"
+		}
+		if len(keys) > 1 {
+			return fmt.Sprintf(" %s%d", arrow, i+1)
+		}
+		return " " + arrow
+	}
+	return ""
+}
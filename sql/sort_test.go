@@ -0,0 +1,71 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func column(rows []Record, col string) []string {
+	out := []string{}
+	for _, r := range rows {
+		out = append(out, r[col])
+	}
+	return out
+}
+
+func TestSortRecordsNumericWithNullsLast(t *testing.T) {
+	rows := []Record{{"n": "10"}, {"n": NullValue}, {"n": "9"}, {"n": "-1.5"}}
+
+	sortRecords(rows, []sortKey{{column: "n"}}, map[string]valueKind{"n": kindNumber})
+	assert.Equal(t, []string{"-1.5", "9", "10", NullValue}, column(rows, "n"))
+
+	sortRecords(rows, []sortKey{{column: "n", descending: true}}, map[string]valueKind{"n": kindNumber})
+	assert.Equal(t, []string{"10", "9", "-1.5", NullValue}, column(rows, "n"))
+}
+
+func TestSortRecordsDates(t *testing.T) {
+	rows := []Record{
+		{"d": "2024-03-01 00:00:00 +0000 UTC"},
+		{"d": "2023-12-31 23:59:59 +0000 UTC"},
+		{"d": "2024-01-15 08:00:00 +0000 UTC"},
+	}
+
+	sortRecords(rows, []sortKey{{column: "d"}}, map[string]valueKind{"d": kindTime})
+
+	assert.Equal(t, "2023-12-31 23:59:59 +0000 UTC", rows[0]["d"])
+	assert.Equal(t, "2024-03-01 00:00:00 +0000 UTC", rows[2]["d"])
+}
+
+func TestSortRecordsMultipleKeys(t *testing.T) {
+	rows := []Record{
+		{"country": "UK", "amount": "5"},
+		{"country": "FR", "amount": "7"},
+		{"country": "UK", "amount": "20"},
+	}
+	kinds := map[string]valueKind{"country": kindString, "amount": kindNumber}
+
+	sortRecords(rows, []sortKey{{column: "country"}, {column: "amount", descending: true}}, kinds)
+
+	assert.Equal(t, []string{"FR", "UK", "UK"}, column(rows, "country"))
+	assert.Equal(t, []string{"7", "20", "5"}, column(rows, "amount"))
+}
+
+func TestToggleSortKey(t *testing.T) {
+	keys := toggleSortKey(nil, "a", false)
+	assert.Equal(t, []sortKey{{column: "a"}}, keys)
+	keys = toggleSortKey(keys, "a", false)
+	assert.Equal(t, []sortKey{{column: "a", descending: true}}, keys)
+	keys = toggleSortKey(keys, "b", true)
+	assert.Equal(t, []sortKey{{column: "a", descending: true}, {column: "b"}}, keys)
+	assert.Equal(t, " ▲2", sortIndicator(keys, "b"))
+	keys = toggleSortKey(keys, "a", true)
+	assert.Equal(t, []sortKey{{column: "b"}}, keys)
+	assert.Nil(t, toggleSortKey([]sortKey{{column: "b", descending: true}}, "b", false))
+}
+
+func TestKindOfType(t *testing.T) {
+	assert.Equal(t, kindNumber, kindOfType("DECIMAL(10,2)"))
+	assert.Equal(t, kindTime, kindOfType("timestamp"))
+	assert.Equal(t, kindString, kindOfType("STRING"))
+}
@@ -0,0 +1,193 @@
+package sql
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+const statsTopValues = 10
+const statsHistogramBins = 10
+const statsBarWidth = 40
+
+type valueCount struct {
+	value string
+	count int
+}
+
+type histogramBin struct {
+	low   float64
+	high  float64
+	count int
+}
+
+// columnStats profiles the values of one column.
+type columnStats struct {
+	column    string
+	kind      valueKind
+	rows      int
+	nulls     int
+	distinct  int
+	min       string
+	max       string
+	numeric   bool
+	mean      float64
+	quantiles []float64 // one per statsPercentiles
+	top       []valueCount
+	histogram []histogramBin
+}
+
+var statsPercentiles = []struct {
+	label string
+	rank  float64
+}{
+	{"p25", 0.25}, {"median", 0.5}, {"p75", 0.75}, {"p90", 0.9}, {"p99", 0.99},
+}
+
+// percentile interpolates linearly between the closest ranks of sorted.
+func percentile(sorted []float64, rank float64) float64 {
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+	position := rank * float64(len(sorted)-1)
+	lower := int(math.Floor(position))
+	upper := int(math.Ceil(position))
+	weight := position - float64(lower)
+	return sorted[lower]*(1-weight) + sorted[upper]*weight
+}
+
+func histogram(sorted []float64, bins int) []histogramBin {
+	if len(sorted) == 0 {
+		return nil
+	}
+	low, high := sorted[0], sorted[len(sorted)-1]
+	if low == high {
+		return []histogramBin{{low, high, len(sorted)}}
+	}
+	width := (high - low) / float64(bins)
+	out := make([]histogramBin, bins)
+	for i := range out {
+		out[i] = histogramBin{low + float64(i)*width, low + float64(i+1)*width, 0}
+	}
+	for _, v := range sorted {
+		index := min(int((v-low)/width), bins-1)
+		out[index].count++
+	}
+	return out
+}
+
+func computeColumnStats(rows []Record, column string, kind valueKind) columnStats {
+	stats := columnStats{column: column, kind: kind, rows: len(rows)}
+	counts := map[string]int{}
+	var numbers []float64
+	allNumeric := true
+
+	for _, r := range rows {
+		value := r[column]
+		if isNull(value) {
+			stats.nulls++
+			continue
+		}
+		if len(counts) == 0 {
+			stats.min, stats.max = value, value
+		}
+		counts[value]++
+		if compareValues(value, stats.min, kind) < 0 {
+			stats.min = value
+		}
+		if compareValues(value, stats.max, kind) > 0 {
+			stats.max = value
+		}
+		if number, ok := parseNumber(value); ok {
+			numbers = append(numbers, number)
+		} else {
+			allNumeric = false
+		}
+	}
+	stats.distinct = len(counts)
+
+	for value, count := range counts {
+		stats.top = append(stats.top, valueCount{value, count})
+	}
+	slices.SortFunc(stats.top, func(a, b valueCount) int {
+		if a.count != b.count {
+			return cmp.Compare(b.count, a.count)
+		}
+		return compareValues(a.value, b.value, kind)
+	})
+	stats.top = stats.top[:min(len(stats.top), statsTopValues)]
+
+	stats.numeric = len(numbers) > 0 && allNumeric && (kind == kindNumber || kind == kindString)
+	if stats.numeric {
+		slices.Sort(numbers)
+		sum := 0.0
+		for _, n := range numbers {
+			sum += n
+		}
+		stats.mean = sum / float64(len(numbers))
+		for _, p := range statsPercentiles {
+			stats.quantiles = append(stats.quantiles, percentile(numbers, p.rank))
+		}
+		stats.histogram = histogram(numbers, statsHistogramBins)
+	}
+	return stats
+}
+
+func formatNumber(n float64) string {
+	if n == math.Trunc(n) && math.Abs(n) < 1e15 {
+		return fmt.Sprintf("%.0f", n)
+	}
+	return fmt.Sprintf("%.4g", n)
+}
+
+func bar(count int, largest int, width int) string {
+	if largest == 0 {
+		return ""
+	}
+	length := int(math.Round(float64(count) / float64(largest) * float64(width)))
+	if count > 0 && length == 0 {
+		return "▏"
+	}
+	return strings.Repeat("█", length)
+}
+
+func (s columnStats) View() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", headerStyle.Render("Profile of "+s.column))
+	fmt.Fprintf(&b, "rows      %d\n", s.rows)
+	fmt.Fprintf(&b, "non-null  %d\n", s.rows-s.nulls)
+	fmt.Fprintf(&b, "nulls     %d\n", s.nulls)
+	fmt.Fprintf(&b, "distinct  %d\n", s.distinct)
+	if s.rows > s.nulls {
+		fmt.Fprintf(&b, "min       %s\n", s.min)
+		fmt.Fprintf(&b, "max       %s\n", s.max)
+	}
+	if s.numeric {
+		fmt.Fprintf(&b, "mean      %s\n", formatNumber(s.mean))
+		for i, p := range statsPercentiles {
+			fmt.Fprintf(&b, "%-9s %s\n", p.label, formatNumber(s.quantiles[i]))
+		}
+		largest := 0
+		for _, bin := range s.histogram {
+			largest = max(largest, bin.count)
+		}
+		fmt.Fprintf(&b, "\n%s\n", headerStyle.Render("Histogram"))
+		for _, bin := range s.histogram {
+			label := fmt.Sprintf("[%s, %s)", formatNumber(bin.low), formatNumber(bin.high))
+			fmt.Fprintf(&b, "%-24s %s %d\n", label, bar(bin.count, largest, statsBarWidth), bin.count)
+		}
+	}
+	if len(s.top) > 0 {
+		fmt.Fprintf(&b, "\n%s\n", headerStyle.Render(fmt.Sprintf("Top %d values", len(s.top))))
+		for _, v := range s.top {
+			label := v.value
+			if len([]rune(label)) > 24 {
+				label = string([]rune(label)[:23]) + "…"
+			}
+			fmt.Fprintf(&b, "%-24s %s %d\n", label, bar(v.count, s.top[0].count, statsBarWidth), v.count)
+		}
+	}
+	return b.String()
+}
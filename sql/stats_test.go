@@ -0,0 +1,44 @@
+package sql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeColumnStatsNumeric(t *testing.T) {
+	rows := []Record{{"n": "1"}, {"n": "2"}, {"n": "2"}, {"n": "10"}, {"n": NullValue}}
+
+	stats := computeColumnStats(rows, "n", kindNumber)
+
+	assert.Equal(t, 5, stats.rows)
+	assert.Equal(t, 1, stats.nulls)
+	assert.Equal(t, 3, stats.distinct)
+	assert.Equal(t, "1", stats.min)
+	assert.Equal(t, "10", stats.max)
+	assert.True(t, stats.numeric)
+	assert.Equal(t, 3.75, stats.mean)
+	assert.Equal(t, 2.0, stats.quantiles[1])
+	assert.Equal(t, valueCount{"2", 2}, stats.top[0])
+	assert.Len(t, stats.histogram, statsHistogramBins)
+	assert.Equal(t, 1, stats.histogram[0].count)
+	assert.Equal(t, 2, stats.histogram[1].count)
+	assert.Equal(t, 1, stats.histogram[statsHistogramBins-1].count)
+}
+
+func TestComputeColumnStatsStrings(t *testing.T) {
+	rows := []Record{{"c": "UK"}, {"c": "FR"}, {"c": "UK"}}
+
+	stats := computeColumnStats(rows, "c", kindString)
+
+	assert.False(t, stats.numeric)
+	assert.Equal(t, "FR", stats.min)
+	assert.Equal(t, "UK", stats.max)
+	assert.Equal(t, []valueCount{{"UK", 2}, {"FR", 1}}, stats.top)
+	assert.Contains(t, stats.View(), "Top 2 values")
+}
+
+func TestPercentileInterpolates(t *testing.T) {
+	assert.Equal(t, 2.5, percentile([]float64{1, 2, 3, 4}, 0.5))
+	assert.Equal(t, 4.0, percentile([]float64{1, 2, 3, 4}, 1))
+}
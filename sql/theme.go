@@ -0,0 +1,72 @@
+package sql
+
+import (
+	"example.com/termquery/theme"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	borderStyle            lipgloss.Style
+	headerStyle            lipgloss.Style
+	highlightStyle         lipgloss.Style
+	statusStyle            lipgloss.Style
+	errorStyle             lipgloss.Style
+	searchMatchStyle       lipgloss.Style
+	searchCurrentStyle     lipgloss.Style
+	chartStyle             lipgloss.Style
+	recordNameStyle        lipgloss.Style
+	planFullScanStyle      lipgloss.Style
+	planExchangeStyle      lipgloss.Style
+	planLargeExchangeStyle lipgloss.Style
+	planSectionStyle       lipgloss.Style
+	planDetailStyle        lipgloss.Style
+	planCursorStyle        lipgloss.Style
+	numberStyle            lipgloss.Style
+	nullStyle              lipgloss.Style
+	timeStyle              lipgloss.Style
+	booleanStyle           lipgloss.Style
+)
+
+func init() {
+	SetTheme(theme.Dark)
+}
+
+// SetTheme restyles the result viewer and the query plan.
+func SetTheme(t theme.Theme) {
+	colour := func(c string) lipgloss.Color { return lipgloss.Color(c) }
+	borderStyle = lipgloss.NewStyle().BorderStyle(lipgloss.NormalBorder()).BorderForeground(colour(t.Border))
+	headerStyle = lipgloss.NewStyle().Bold(true).Underline(true).Foreground(colour(t.Header))
+	highlightStyle = lipgloss.NewStyle().Foreground(colour(t.Selected)).Background(colour(t.SelectedBackground))
+	statusStyle = lipgloss.NewStyle().Foreground(colour(t.Muted))
+	errorStyle = lipgloss.NewStyle().Foreground(colour(t.Error))
+	searchMatchStyle = lipgloss.NewStyle().Foreground(colour(t.MatchText)).Background(colour(t.Match))
+	searchCurrentStyle = lipgloss.NewStyle().Foreground(colour(t.MatchText)).Background(colour(t.CurrentMatch)).Bold(true)
+	chartStyle = lipgloss.NewStyle().Foreground(colour(t.Accent))
+	recordNameStyle = lipgloss.NewStyle().Bold(true).Foreground(colour(t.Header))
+	planFullScanStyle = lipgloss.NewStyle().Foreground(colour(t.Error)).Bold(true)
+	planExchangeStyle = lipgloss.NewStyle().Foreground(colour(t.Accent))
+	planLargeExchangeStyle = lipgloss.NewStyle().Foreground(colour(t.Warning)).Bold(true)
+	planSectionStyle = lipgloss.NewStyle().Foreground(colour(t.Header)).Bold(true).Underline(true)
+	planDetailStyle = lipgloss.NewStyle().Foreground(colour(t.Muted))
+	planCursorStyle = lipgloss.NewStyle().Background(colour(t.SelectedBackground))
+	numberStyle = lipgloss.NewStyle().Foreground(colour(t.Number))
+	nullStyle = lipgloss.NewStyle().Foreground(colour(t.Null)).Italic(true)
+	timeStyle = lipgloss.NewStyle().Foreground(colour(t.Time))
+	booleanStyle = lipgloss.NewStyle().Foreground(colour(t.Boolean))
+}
+
+// valueStyle colours a cell by the type of its column, and NULLs apart.
+func (m *model) valueStyle(column string, value string) (lipgloss.Style, bool) {
+	if isNull(value) {
+		return nullStyle, true
+	}
+	switch m.kinds[column] {
+	case kindNumber:
+		return numberStyle, true
+	case kindTime:
+		return timeStyle, true
+	case kindBoolean:
+		return booleanStyle, true
+	}
+	return lipgloss.Style{}, false
+}
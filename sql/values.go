@@ -0,0 +1,114 @@
+package sql
+
+import (
+	"cmp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// valueKind groups database types by how their values compare.
+type valueKind int
+
+const (
+	kindString valueKind = iota
+	kindNumber
+	kindTime
+	kindBoolean
+)
+
+// timeLayouts are the ways temporal values appear in results, starting with
+// how fmt prints a time.Time.
+var timeLayouts = []string{
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+// kindOfType maps a database type name such as BIGINT or DECIMAL(10,2) to a valueKind.
+func kindOfType(databaseType string) valueKind {
+	name, _, _ := strings.Cut(strings.ToUpper(databaseType), "(")
+	switch name {
+	case "TINYINT", "SMALLINT", "INT", "INTEGER", "BIGINT", "LONG", "FLOAT", "REAL", "DOUBLE", "DECIMAL", "NUMERIC", "DEC":
+		return kindNumber
+	case "DATE", "TIMESTAMP", "TIMESTAMP_NTZ", "TIMESTAMP_LTZ":
+		return kindTime
+	case "BOOLEAN":
+		return kindBoolean
+	}
+	return kindString
+}
+
+func isNull(value string) bool {
+	return value == NullValue
+}
+
+func parseNumber(value string) (float64, bool) {
+	number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	return number, err == nil
+}
+
+func parseTime(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// inferKind guesses the kind of a column without a known type from its values.
+func inferKind(rows []Record, col string) valueKind {
+	seen := false
+	numbers, times := true, true
+	for _, r := range rows {
+		value := r[col]
+		if isNull(value) || value == "" {
+			continue
+		}
+		seen = true
+		if _, ok := parseNumber(value); !ok {
+			numbers = false
+		}
+		if _, ok := parseTime(value); !ok {
+			times = false
+		}
+		if !numbers && !times {
+			return kindString
+		}
+	}
+	switch {
+	case !seen:
+		return kindString
+	case numbers:
+		return kindNumber
+	case times:
+		return kindTime
+	}
+	return kindString
+}
+
+// compareValues orders two non NULL values of the given kind, falling back to
+// string comparison for values that do not parse.
+func compareValues(a string, b string, kind valueKind) int {
+	switch kind {
+	case kindNumber:
+		x, okX := parseNumber(a)
+		y, okY := parseNumber(b)
+		if okX && okY {
+			return cmp.Compare(x, y)
+		}
+	case kindTime:
+		x, okX := parseTime(a)
+		y, okY := parseTime(b)
+		if okX && okY {
+			return x.Compare(y)
+		}
+	case kindBoolean:
+		return cmp.Compare(strings.ToLower(a), strings.ToLower(b))
+	}
+	return strings.Compare(a, b)
+}
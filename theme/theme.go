@@ -0,0 +1,193 @@
+package theme
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+
+	"example.com/termquery/utils"
+	"github.com/muesli/termenv"
+)
+
+// Auto picks the dark or light theme to suit the terminal background.
+const Auto = "auto"
+
+// Theme holds the colours of every view. A colour is an ANSI colour number
+// ("0"-"255") or a hex value ("#rrggbb"); lipgloss downsamples either to
+// what the terminal supports. An empty colour leaves the terminal default.
+type Theme struct {
+	Name               string
+	Border             string
+	Header             string
+	Selected           string
+	SelectedBackground string
+	Muted              string
+	Error              string
+	Warning            string
+	Accent             string
+	MatchText          string
+	Match              string
+	CurrentMatch       string
+	Number             string
+	Null               string
+	Time               string
+	Boolean            string
+}
+
+var Dark = Theme{
+	Name:               "dark",
+	Border:             "240",
+	Header:             "250",
+	Selected:           "229",
+	SelectedBackground: "57",
+	Muted:              "244",
+	Error:              "196",
+	Warning:            "214",
+	Accent:             "205",
+	MatchText:          "0",
+	Match:              "178",
+	CurrentMatch:       "208",
+	Number:             "111",
+	Null:               "241",
+	Time:               "150",
+	Boolean:            "180",
+}
+
+var Light = Theme{
+	Name:               "light",
+	Border:             "250",
+	Header:             "236",
+	Selected:           "0",
+	SelectedBackground: "153",
+	Muted:              "243",
+	Error:              "160",
+	Warning:            "166",
+	Accent:             "162",
+	MatchText:          "0",
+	Match:              "222",
+	CurrentMatch:       "214",
+	Number:             "25",
+	Null:               "248",
+	Time:               "28",
+	Boolean:            "130",
+}
+
+// HighContrast only uses the 16 basic colours, at full brightness.
+var HighContrast = Theme{
+	Name:               "high-contrast",
+	Border:             "15",
+	Header:             "15",
+	Selected:           "0",
+	SelectedBackground: "11",
+	Muted:              "15",
+	Error:              "9",
+	Warning:            "11",
+	Accent:             "14",
+	MatchText:          "0",
+	Match:              "14",
+	CurrentMatch:       "9",
+	Number:             "14",
+	Null:               "13",
+	Time:               "10",
+	Boolean:            "11",
+}
+
+var builtIn = map[string]Theme{
+	Dark.Name:         Dark,
+	Light.Name:        Light,
+	HighContrast.Name: HighContrast,
+}
+
+// colours names the colours of t as they are set in the config.
+func (t *Theme) colours() map[string]*string {
+	return map[string]*string{
+		"border":              &t.Border,
+		"header":              &t.Header,
+		"selected":            &t.Selected,
+		"selected_background": &t.SelectedBackground,
+		"muted":               &t.Muted,
+		"error":               &t.Error,
+		"warning":             &t.Warning,
+		"accent":              &t.Accent,
+		"match_text":          &t.MatchText,
+		"match":               &t.Match,
+		"current_match":       &t.CurrentMatch,
+		"number":              &t.Number,
+		"null":                &t.Null,
+		"time":                &t.Time,
+		"boolean":             &t.Boolean,
+	}
+}
+
+var hexColour = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+func validColour(colour string) bool {
+	if colour == "" || hexColour.MatchString(colour) {
+		return true
+	}
+	n, err := strconv.Atoi(colour)
+	return err == nil && n >= 0 && n <= 255
+}
+
+// Resolve finds the theme called name among the built-in themes and the user
+// themes from the config. A user theme starts from the theme named by its
+// "base" setting, dark by default, and overrides some of its colours.
+// darkBackground is only asked when the theme is picked automatically.
+func Resolve(name string, user map[string]map[string]string, darkBackground func() bool) (Theme, error) {
+	return resolve(name, user, darkBackground, nil)
+}
+
+func resolve(name string, user map[string]map[string]string, darkBackground func() bool, seen []string) (Theme, error) {
+	if name == "" || name == Auto {
+		if darkBackground() {
+			return Dark, nil
+		}
+		return Light, nil
+	}
+	settings, ok := user[name]
+	if !ok {
+		if t, ok := builtIn[name]; ok {
+			return t, nil
+		}
+		return Theme{}, fmt.Errorf("unknown theme %q", name)
+	}
+	if slices.Contains(seen, name) {
+		return Theme{}, fmt.Errorf("theme %q is based on itself", name)
+	}
+
+	base := settings["base"]
+	if base == "" {
+		base = Dark.Name
+	}
+	t, err := resolve(base, user, darkBackground, append(seen, name))
+	if err != nil {
+		return Theme{}, err
+	}
+	t.Name = name
+	colours := t.colours()
+	for _, setting := range slices.Sorted(maps.Keys(settings)) {
+		if setting == "base" {
+			continue
+		}
+		colour, ok := colours[setting]
+		if !ok {
+			return Theme{}, fmt.Errorf("theme %q: unknown colour %q", name, setting)
+		}
+		if !validColour(settings[setting]) {
+			return Theme{}, fmt.Errorf("theme %q: %s is %q, expected 0-255 or #rrggbb", name, setting, settings[setting])
+		}
+		*colour = settings[setting]
+	}
+	return t, nil
+}
+
+// Profile is the colour profile to render with: plain text when NO_COLOR is
+// set, see https://no-color.org, or else what was detected for the terminal.
+func Profile(envFunc utils.GetEnvFunc, detected termenv.Profile) termenv.Profile {
+	if envFunc("NO_COLOR") != "" {
+		return termenv.Ascii
+	}
+	return detected
+}
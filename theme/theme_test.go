@@ -0,0 +1,67 @@
+package theme
+
+import (
+	"testing"
+
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+)
+
+func dark() bool  { return true }
+func light() bool { return false }
+
+func TestResolveBuiltIn(t *testing.T) {
+	resolved, err := Resolve("high-contrast", nil, dark)
+	assert.Nil(t, err)
+	assert.Equal(t, HighContrast, resolved)
+
+	resolved, _ = Resolve(Auto, nil, dark)
+	assert.Equal(t, Dark, resolved)
+	resolved, _ = Resolve("", nil, light)
+	assert.Equal(t, Light, resolved)
+
+	_, err = Resolve("neon", nil, dark)
+	assert.EqualError(t, err, `unknown theme "neon"`)
+}
+
+func TestResolveUserTheme(t *testing.T) {
+	user := map[string]map[string]string{
+		"solarized": {"base": "light", "header": "#268bd2", "null": ""},
+		"mine":      {"base": "solarized", "number": "33"},
+	}
+	resolved, err := Resolve("mine", user, dark)
+	assert.Nil(t, err)
+	assert.Equal(t, "mine", resolved.Name)
+	assert.Equal(t, "#268bd2", resolved.Header)
+	assert.Equal(t, "33", resolved.Number)
+	assert.Equal(t, "", resolved.Null)
+	assert.Equal(t, Light.Border, resolved.Border)
+}
+
+func TestResolveUserThemeErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		user    map[string]map[string]string
+		message string
+	}{
+		{"unknown colour", map[string]map[string]string{"t": {"sparkle": "1"}}, `theme "t": unknown colour "sparkle"`},
+		{"bad colour", map[string]map[string]string{"t": {"header": "blue"}}, `theme "t": header is "blue", expected 0-255 or #rrggbb`},
+		{"out of range", map[string]map[string]string{"t": {"header": "256"}}, `theme "t": header is "256", expected 0-255 or #rrggbb`},
+		{"cycle", map[string]map[string]string{"t": {"base": "u"}, "u": {"base": "t"}}, `theme "t" is based on itself`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Resolve("t", test.user, dark)
+			assert.EqualError(t, err, test.message)
+		})
+	}
+}
+
+func TestProfileHonoursNoColor(t *testing.T) {
+	env := map[string]string{}
+	getenv := func(key string) string { return env[key] }
+	assert.Equal(t, termenv.ANSI256, Profile(getenv, termenv.ANSI256))
+
+	env["NO_COLOR"] = "1"
+	assert.Equal(t, termenv.Ascii, Profile(getenv, termenv.TrueColor))
+}